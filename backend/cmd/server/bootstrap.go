@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"job-hunt/backend/internal/pipeline"
+)
+
+// loadPipelineFile creates the pipelines listed in the YAML or JSON file at path, a list of
+// definitions in the same shape POST /pipelines accepts. Pipelines that already exist are left as
+// they are, so restarting with the same file is harmless. Definitions that fail validation are
+// logged and skipped, or fail startup when strict is set.
+func loadPipelineFile(svc *pipeline.Service, path string, strict bool) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read pipeline file: %w", err)
+	}
+	if ext := strings.ToLower(filepath.Ext(path)); ext == ".yaml" || ext == ".yml" {
+		// YAML is converted to JSON so fields keep the API's names and unknown ones are rejected alike
+		var doc []map[string]any
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("parse %s: %w", path, err)
+		}
+		for _, cfg := range doc {
+			quoteConfigValues(cfg)
+		}
+		if data, err = json.Marshal(doc); err != nil {
+			return fmt.Errorf("parse %s: %w", path, err)
+		}
+	}
+	var cfgs []pipeline.Config
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfgs); err != nil {
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	var invalid int
+	for i, cfg := range cfgs {
+		err := svc.Create(cfg)
+		switch {
+		case err == nil:
+			slog.Info("created pipeline from file", "pipeline", cfg.Name, "file", path)
+		case errors.Is(err, pipeline.ErrPipelineExists):
+			slog.Info("pipeline from file already exists, leaving it unchanged", "pipeline", cfg.Name, "file", path)
+		case errors.Is(err, pipeline.ErrValidation):
+			invalid++
+			slog.Error("invalid pipeline in file", "pipeline", cfg.Name, "index", i, "file", path, "error", err)
+		default:
+			return fmt.Errorf("create pipeline %s: %w", cfg.Name, err)
+		}
+	}
+	if strict && invalid > 0 {
+		return fmt.Errorf("%d of %d pipelines in %s are invalid", invalid, len(cfgs), path)
+	}
+	return nil
+}
+
+// stringMaps are the pipeline fields, at the top level or in each of sources and destinations,
+// whose values are all strings.
+var stringMaps = []string{"sourceConfig", "destConfig", "transformConfig", "mapping", "config"}
+
+// quoteConfigValues turns the numbers and booleans YAML finds in a definition's string maps, such as
+// port: 5432, back into the strings the JSON form requires.
+func quoteConfigValues(cfg map[string]any) {
+	for _, field := range stringMaps {
+		if m, ok := cfg[field].(map[string]any); ok {
+			for k, v := range m {
+				switch v.(type) {
+				case int, float64, bool:
+					m[k] = fmt.Sprint(v)
+				}
+			}
+		}
+	}
+	for _, list := range []string{"sources", "destinations"} {
+		items, _ := cfg[list].([]any)
+		for _, item := range items {
+			if m, ok := item.(map[string]any); ok {
+				quoteConfigValues(m)
+			}
+		}
+	}
+}
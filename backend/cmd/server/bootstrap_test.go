@@ -0,0 +1,114 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"job-hunt/backend/internal/connectors"
+	"job-hunt/backend/internal/pipeline"
+)
+
+const yamlPipelines = `# declared pipelines
+- name: orders
+  sourceType: mysql
+  sourceConfig: {host: db.internal, port: 3306, user: etl, password: secret, database: shop}
+  destType: postgres
+  destConfig:
+    host: warehouse
+    port: 5432
+    user: etl
+    password: secret
+    database: analytics
+  maxRetries: 2
+`
+
+const jsonPipelines = `[{
+	"name": "orders",
+	"sourceType": "mysql",
+	"sourceConfig": {"host": "db.internal", "port": "3306", "user": "etl", "password": "secret", "database": "shop"},
+	"destType": "postgres",
+	"destConfig": {"host": "warehouse", "port": "5432", "user": "etl", "password": "secret", "database": "analytics"},
+	"maxRetries": 2
+}]`
+
+func newBootstrapService(t *testing.T) (*pipeline.Service, pipeline.Store) {
+	t.Helper()
+	reg, err := connectors.NewRegistry()
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+	store := pipeline.NewMemoryStore()
+	return pipeline.NewService(reg, store), store
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPipelineFile(t *testing.T) {
+	for _, tc := range []struct{ file, content string }{
+		{"pipelines.yaml", yamlPipelines},
+		{"pipelines.yml", yamlPipelines},
+		{"pipelines.json", jsonPipelines},
+	} {
+		t.Run(tc.file, func(t *testing.T) {
+			svc, store := newBootstrapService(t)
+			path := writeFile(t, tc.file, tc.content)
+			if err := loadPipelineFile(svc, path, true); err != nil {
+				t.Fatalf("loadPipelineFile: %v", err)
+			}
+			cfg, ok, err := store.Load("orders")
+			if err != nil || !ok {
+				t.Fatalf("orders not created: %v", err)
+			}
+			if cfg.SourceConfig["port"] != "3306" || cfg.DestConfig["password"] != "secret" || cfg.MaxRetries != 2 {
+				t.Fatalf("created %+v", cfg)
+			}
+
+			// loading the same file again leaves the existing pipeline alone
+			if err := loadPipelineFile(svc, path, true); err != nil {
+				t.Fatalf("reload: %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadPipelineFileInvalid(t *testing.T) {
+	content := yamlPipelines + `- name: broken
+  sourceType: nope
+  destType: postgres
+`
+	path := writeFile(t, "pipelines.yaml", content)
+
+	svc, store := newBootstrapService(t)
+	if err := loadPipelineFile(svc, path, false); err != nil {
+		t.Fatalf("non-strict load: %v", err)
+	}
+	if _, ok, _ := store.Load("orders"); !ok {
+		t.Fatal("valid pipeline was not created next to the invalid one")
+	}
+	if _, ok, _ := store.Load("broken"); ok {
+		t.Fatal("invalid pipeline was created")
+	}
+
+	svc, _ = newBootstrapService(t)
+	err := loadPipelineFile(svc, path, true)
+	if err == nil || !strings.Contains(err.Error(), "1 of 2 pipelines") {
+		t.Fatalf("strict load = %v, want the invalid count", err)
+	}
+}
+
+func TestLoadPipelineFileUnknownField(t *testing.T) {
+	path := writeFile(t, "pipelines.yaml", "- name: orders\n  souceType: mysql\n")
+	svc, _ := newBootstrapService(t)
+	if err := loadPipelineFile(svc, path, false); err == nil || !strings.Contains(err.Error(), "souceType") {
+		t.Fatalf("loadPipelineFile = %v, want the unknown field named", err)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"strings"
+
+	"job-hunt/backend/internal/connectors"
+	"job-hunt/backend/internal/pipeline"
+)
+
+// connectorDefaults holds default connector config keyed by connector type and then name, e.g.
+// {"source": {"mysql": {"host": "db.internal", "port": "3306"}}}.
+type connectorDefaults map[connectors.ConnectorType]map[string]map[string]string
+
+// applyConnectorDefaults registers the defaults in the JSON file at path and those given inline as
+// JSON. Inline values override the file's key by key.
+func applyConnectorDefaults(svc *pipeline.Service, path, inline string) error {
+	merged := connectorDefaults{}
+	add := func(label string, data []byte) error {
+		var d connectorDefaults
+		if err := json.Unmarshal(data, &d); err != nil {
+			return fmt.Errorf("parse %s: %w", label, err)
+		}
+		for typ, byName := range d {
+			if merged[typ] == nil {
+				merged[typ] = map[string]map[string]string{}
+			}
+			for name, config := range byName {
+				if merged[typ][name] == nil {
+					merged[typ][name] = map[string]string{}
+				}
+				maps.Copy(merged[typ][name], config)
+			}
+		}
+		return nil
+	}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read connector defaults: %w", err)
+		}
+		if err := add(path, data); err != nil {
+			return err
+		}
+	}
+	if inline != "" {
+		if err := add("CONNECTOR_DEFAULTS", []byte(inline)); err != nil {
+			return err
+		}
+	}
+	for typ, byName := range merged {
+		for name, config := range byName {
+			if err := svc.SetConnectorDefaults(typ, name, config); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// registerAliases adds connector aliases written as alias=name, e.g. pg=postgres.
+func registerAliases(reg *connectors.Registry, entries []string) error {
+	for _, entry := range entries {
+		alias, name, ok := strings.Cut(entry, "=")
+		if !ok {
+			return fmt.Errorf("connector alias %q must be written alias=name", entry)
+		}
+		if err := reg.Alias(strings.TrimSpace(name), strings.TrimSpace(alias)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
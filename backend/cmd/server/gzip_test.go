@@ -0,0 +1,227 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"job-hunt/backend/internal/connectors"
+	"job-hunt/backend/internal/pipeline"
+)
+
+// getEncoded requests path from srv with the given Accept-Encoding, returning the response and its
+// raw, still encoded body.
+func getEncoded(t *testing.T, srv *httptest.Server, path, encoding string) (*http.Response, []byte) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encoding != "" {
+		// set explicitly so the transport leaves the body compressed
+		req.Header.Set("Accept-Encoding", encoding)
+	}
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, body
+}
+
+func TestCompressResponses(t *testing.T) {
+	large := strings.Repeat("x", gzipMinSize)
+	srv := httptest.NewServer(compressResponses(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		if r.URL.Path == "/small" {
+			io.WriteString(w, "small")
+			return
+		}
+		// written in pieces so the size threshold is crossed part way
+		io.WriteString(w, large[:10])
+		io.WriteString(w, large[10:])
+	})))
+	defer srv.Close()
+
+	for _, tc := range []struct {
+		path, encoding string
+		status         int
+		gzipped        bool
+	}{
+		{"/large", "gzip", http.StatusOK, true},
+		{"/large", "deflate, GZIP;q=0.5", http.StatusOK, true},
+		{"/missing", "gzip", http.StatusNotFound, true},
+		{"/small", "gzip", http.StatusOK, false},
+		{"/large", "", http.StatusOK, false},
+		{"/large", "gzip;q=0", http.StatusOK, false},
+		{"/large", "br", http.StatusOK, false},
+	} {
+		t.Run(tc.path+" "+tc.encoding, func(t *testing.T) {
+			resp, body := getEncoded(t, srv, tc.path, tc.encoding)
+			if resp.StatusCode != tc.status {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tc.status)
+			}
+			if !strings.Contains(resp.Header.Get("Vary"), "Accept-Encoding") {
+				t.Fatalf("Vary = %q", resp.Header.Get("Vary"))
+			}
+			if gzipped := resp.Header.Get("Content-Encoding") == "gzip"; gzipped != tc.gzipped {
+				t.Fatalf("Content-Encoding = %q, want gzip %v", resp.Header.Get("Content-Encoding"), tc.gzipped)
+			}
+			if tc.gzipped {
+				zr, err := gzip.NewReader(strings.NewReader(string(body)))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if body, err = io.ReadAll(zr); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if want := map[bool]string{true: "small", false: large}[tc.path == "/small"]; string(body) != want {
+				t.Fatalf("body has %d bytes, want %d", len(body), len(want))
+			}
+		})
+	}
+}
+
+// TestCompressedStreamFlushes checks that a flushed event reaches the client while the handler is
+// still writing, rather than waiting in the compression buffer.
+func TestCompressedStreamFlushes(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(compressResponses(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i := range 2 {
+			fmt.Fprintf(w, "data: %d\n\n", i)
+			http.NewResponseController(w).Flush()
+			<-release
+		}
+	})))
+	defer srv.Close()
+	defer close(release)
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.Header.Get("Content-Encoding") != "" {
+		t.Fatalf("a small flushed stream was sent with Content-Encoding %q", resp.Header.Get("Content-Encoding"))
+	}
+	lines := bufio.NewReader(resp.Body)
+	for i := range 2 {
+		line, err := lines.ReadString('\n')
+		if err != nil || line != fmt.Sprintf("data: %d\n", i) {
+			t.Fatalf("event %d = %q %v", i, line, err)
+		}
+		lines.ReadString('\n')
+		release <- struct{}{}
+	}
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	for header, want := range map[string]bool{
+		"":                    false,
+		"gzip":                true,
+		"GZip":                true,
+		"deflate, gzip":       true,
+		"gzip;q=0.1":          true,
+		"gzip; q=1.0":         true,
+		"gzip;q=0":            false,
+		"gzip;q=0.0":          false,
+		"gzip;q=abc":          false,
+		"identity, br":        false,
+		"x-gzip":              false,
+		"br;q=1, gzip;q=0.01": true,
+	} {
+		if got := acceptsGzip(header); got != want {
+			t.Errorf("acceptsGzip(%q) = %v, want %v", header, got, want)
+		}
+	}
+}
+
+// TestConnectorsETag checks conditional GETs of the connectors list through the compression
+// middleware: a 304 carries the tag but no body or Content-Encoding.
+func TestConnectorsETag(t *testing.T) {
+	reg, err := connectors.NewRegistry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := pipeline.NewService(reg, pipeline.NewMemoryStore())
+	var ready atomic.Bool
+	ready.Store(true)
+	srv := httptest.NewServer(compressResponses(newMux(reg, svc, defaultMaxBodyBytes, false, &ready)))
+	defer srv.Close()
+
+	get := func(path, ifNoneMatch string) (*http.Response, []byte) {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Accept-Encoding", "gzip")
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		resp, err := srv.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp, body
+	}
+
+	resp, _ := get("/connectors", "")
+	tag := resp.Header.Get("ETag")
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Encoding") != "gzip" || !strings.HasPrefix(tag, `W/"`) {
+		t.Fatalf("GET /connectors = %d, Content-Encoding %q, ETag %q", resp.StatusCode, resp.Header.Get("Content-Encoding"), tag)
+	}
+
+	for _, tc := range []struct {
+		ifNoneMatch string
+		want        int
+	}{
+		{tag, http.StatusNotModified},
+		{strings.TrimPrefix(tag, "W/"), http.StatusNotModified},
+		{`"other", ` + tag, http.StatusNotModified},
+		{"*", http.StatusNotModified},
+		{`W/"other"`, http.StatusOK},
+	} {
+		resp, body := get("/connectors", tc.ifNoneMatch)
+		if resp.StatusCode != tc.want {
+			t.Fatalf("If-None-Match %s = %d, want %d", tc.ifNoneMatch, resp.StatusCode, tc.want)
+		}
+		if resp.Header.Get("ETag") != tag {
+			t.Fatalf("If-None-Match %s got ETag %q, want %q", tc.ifNoneMatch, resp.Header.Get("ETag"), tag)
+		}
+		if tc.want == http.StatusNotModified && (len(body) != 0 || resp.Header.Get("Content-Encoding") != "") {
+			t.Fatalf("304 has %d body bytes and Content-Encoding %q", len(body), resp.Header.Get("Content-Encoding"))
+		}
+	}
+
+	if resp, _ := get("/connectors?type=source", tag); resp.StatusCode != http.StatusOK {
+		t.Fatalf("filtered list matched the full list's tag: %d", resp.StatusCode)
+	}
+	if err := reg.RegisterDestination(&rejectingDestination{}); err != nil {
+		t.Fatal(err)
+	}
+	if resp, _ := get("/connectors", tag); resp.StatusCode != http.StatusOK || resp.Header.Get("ETag") == tag {
+		t.Fatalf("after registering a connector got %d with ETag %q", resp.StatusCode, resp.Header.Get("ETag"))
+	}
+}
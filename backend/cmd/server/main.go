@@ -1,21 +1,200 @@
 package main
 
 import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
-	"log"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
+	"os/signal"
+	"reflect"
+	"slices"
+	"strconv"
 	"strings"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"job-hunt/backend/internal/connectors"
 	"job-hunt/backend/internal/pipeline"
 )
 
+const (
+	// shutdownGrace bounds how long in-flight runs may continue after a shutdown signal.
+	shutdownGrace = 30 * time.Second
+	// defaultSampleSize is the number of records returned by the sample endpoint without ?limit=.
+	defaultSampleSize = 10
+	// healthCheckTimeout bounds the connector probes behind /health/connectors.
+	healthCheckTimeout = 5 * time.Second
+	// defaultMaxBodyBytes caps request bodies when MAX_BODY_BYTES is unset.
+	defaultMaxBodyBytes = 1 << 20
+	// exportVersion identifies the layout of the export document.
+	exportVersion = 1
+	// busyRetryAfter is the Retry-After sent with runs shed by the MAX_CONCURRENT_RUNS ceiling.
+	busyRetryAfter = 5 * time.Second
+)
+
+// Build information, set at link time with
+// -ldflags "-X main.version=... -X main.commit=... -X main.buildTime=...".
+var (
+	version   = "dev"
+	commit    = "unknown"
+	buildTime = "unknown"
+)
+
 func main() {
-	registry := connectors.NewRegistry()
-	svc := pipeline.NewService(registry)
+	configFile := flag.String("config", "", "YAML or JSON file of pipeline definitions to create at startup")
+	flag.Parse()
+	slog.SetDefault(slog.New(requestIDHandler{slog.NewJSONHandler(os.Stdout, nil)}))
+
+	registry, err := connectors.NewRegistry()
+	if err != nil {
+		slog.Error("build connector registry", "error", err)
+		os.Exit(1)
+	}
+	if err := registerAliases(registry, splitList(os.Getenv("CONNECTOR_ALIASES"))); err != nil {
+		slog.Error("register connector aliases", "error", err)
+		os.Exit(1)
+	}
+	store, err := openStore(os.Getenv("PIPELINE_STORE"), os.Getenv("PIPELINE_STORE_PATH"))
+	if err != nil {
+		slog.Error("open pipeline store", "error", err)
+		os.Exit(1)
+	}
+	store, err = encryptStore(store, registry, os.Getenv("STORE_ENCRYPTION_KEY"), os.Getenv("STORE_PREVIOUS_ENCRYPTION_KEY"))
+	if err != nil {
+		slog.Error("encrypt pipeline store", "error", err)
+		os.Exit(1)
+	}
+	svc := pipeline.NewService(registry, store)
+	if err := applyConnectorDefaults(svc, os.Getenv("CONNECTOR_DEFAULTS_FILE"), os.Getenv("CONNECTOR_DEFAULTS")); err != nil {
+		slog.Error("load connector defaults", "error", err)
+		os.Exit(1)
+	}
+	maxBodyBytes, err := strconv.ParseInt(cmp.Or(os.Getenv("MAX_BODY_BYTES"), strconv.Itoa(defaultMaxBodyBytes)), 10, 64)
+	if err != nil || maxBodyBytes <= 0 {
+		slog.Error("MAX_BODY_BYTES must be a positive number of bytes", "value", os.Getenv("MAX_BODY_BYTES"))
+		os.Exit(1)
+	}
+	strictModes := os.Getenv("STRICT_MODE_PAIRING") == "true"
+	svc.SetStrictModes(strictModes)
+	svc.SetPrivateWebhooks(os.Getenv("WEBHOOK_ALLOW_PRIVATE") == "true")
+	apiKeys, err := parseAPIKeys(splitList(os.Getenv("API_KEYS")))
+	if err != nil {
+		slog.Error("parse API_KEYS", "error", err)
+		os.Exit(1)
+	}
+	if len(apiKeys) == 0 {
+		slog.Warn("API_KEYS is not set, the API is unauthenticated")
+	}
+	workers, err := strconv.Atoi(cmp.Or(os.Getenv("RUN_WORKERS"), strconv.Itoa(pipeline.DefaultWorkers)))
+	if err != nil || workers <= 0 {
+		slog.Error("RUN_WORKERS must be a positive number of concurrent runs", "value", os.Getenv("RUN_WORKERS"))
+		os.Exit(1)
+	}
+	svc.SetWorkers(workers)
+	breakerThreshold, err := strconv.Atoi(cmp.Or(os.Getenv("BREAKER_THRESHOLD"), strconv.Itoa(pipeline.DefaultBreakerThreshold)))
+	if err != nil || breakerThreshold < 0 {
+		slog.Error("BREAKER_THRESHOLD must be a non-negative number of failures", "value", os.Getenv("BREAKER_THRESHOLD"))
+		os.Exit(1)
+	}
+	breakerCooldown, err := strconv.Atoi(cmp.Or(os.Getenv("BREAKER_COOLDOWN_SECONDS"), strconv.Itoa(int(pipeline.DefaultBreakerCooldown.Seconds()))))
+	if err != nil || breakerCooldown < 0 {
+		slog.Error("BREAKER_COOLDOWN_SECONDS must be a non-negative number of seconds", "value", os.Getenv("BREAKER_COOLDOWN_SECONDS"))
+		os.Exit(1)
+	}
+	svc.SetCircuitBreaker(breakerThreshold, time.Duration(breakerCooldown)*time.Second)
+	jobTTL, err := strconv.Atoi(cmp.Or(os.Getenv("JOB_TTL_SECONDS"), strconv.Itoa(int(pipeline.DefaultJobTTL.Seconds()))))
+	if err != nil || jobTTL <= 0 {
+		slog.Error("JOB_TTL_SECONDS must be a positive number of seconds", "value", os.Getenv("JOB_TTL_SECONDS"))
+		os.Exit(1)
+	}
+	maxJobs, err := strconv.Atoi(cmp.Or(os.Getenv("MAX_FINISHED_JOBS"), strconv.Itoa(pipeline.DefaultMaxFinishedJobs)))
+	if err != nil || maxJobs <= 0 {
+		slog.Error("MAX_FINISHED_JOBS must be a positive number of jobs", "value", os.Getenv("MAX_FINISHED_JOBS"))
+		os.Exit(1)
+	}
+	svc.SetJobRetention(time.Duration(jobTTL)*time.Second, maxJobs)
+	maxRuns, err := strconv.Atoi(cmp.Or(os.Getenv("MAX_CONCURRENT_RUNS"), "0"))
+	if err != nil || maxRuns < 0 {
+		slog.Error("MAX_CONCURRENT_RUNS must be a non-negative number of runs", "value", os.Getenv("MAX_CONCURRENT_RUNS"))
+		os.Exit(1)
+	}
+	svc.SetMaxConcurrentRuns(maxRuns)
+	runRateLimit, err := strconv.Atoi(cmp.Or(os.Getenv("RUN_RATE_LIMIT"), "0"))
+	if err != nil || runRateLimit < 0 {
+		slog.Error("RUN_RATE_LIMIT must be a non-negative number of runs per minute", "value", os.Getenv("RUN_RATE_LIMIT"))
+		os.Exit(1)
+	}
+	if path := cmp.Or(*configFile, os.Getenv("PIPELINE_CONFIG_FILE")); path != "" {
+		if err := loadPipelineFile(svc, path, os.Getenv("PIPELINE_CONFIG_STRICT") == "true"); err != nil {
+			slog.Error("load pipeline file", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	// ready is set once startup completes and cleared again when shutdown begins
+	var ready atomic.Bool
+
+	mux := newMux(registry, svc, maxBodyBytes, strictModes, &ready)
+
+	addr := ":8080"
+	if port := os.Getenv("PORT"); port != "" {
+		addr = ":" + port
+	}
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           tagRequests(logRequests(recoverPanics(compressResponses(cors(splitList(os.Getenv("ALLOWED_ORIGINS")), requireAPIKey(apiKeys, limitRuns(runRateLimit, mux))))))),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	svc.StartScheduler(ctx)
+	ready.Store(true)
+
+	go func() {
+		slog.Info("server listening", "addr", addr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("server stopped", "error", err)
+			os.Exit(1)
+		}
+	}()
+
+	<-ctx.Done()
+	ready.Store(false)
+	slog.Info("shutting down", "inFlightRuns", svc.ActiveRuns(), "graceSeconds", shutdownGrace.Seconds())
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownGrace)
+	defer cancel()
+
+	// stop accepting requests first, then give the remaining runs (including async jobs)
+	// the rest of the grace period before cancelling them
+	httpDone := make(chan struct{})
+	go func() {
+		defer close(httpDone)
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			slog.Warn("http shutdown", "error", err)
+		}
+	}()
+	if err := svc.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("cancelled in-flight runs at shutdown", "error", err)
+	}
+	<-httpDone
+	slog.Info("server stopped")
+}
 
+// newMux routes the API onto registry and svc. ready gates /ready; the caller wraps the mux in
+// the middleware chain.
+func newMux(registry *connectors.Registry, svc *pipeline.Service, maxBodyBytes int64, strictModes bool, ready *atomic.Bool) *http.ServeMux {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -23,24 +202,173 @@ func main() {
 		w.Write([]byte("\"ok\""))
 	})
 
+	mux.HandleFunc("/ready", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if !ready.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte("\"starting\""))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("\"ready\""))
+	})
+
+	mux.HandleFunc("/version", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		writeJSON(w, map[string]string{"version": version, "commit": commit, "buildTime": buildTime})
+	})
+
+	mux.HandleFunc("/health/connectors", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		ctx, cancel := context.WithTimeout(r.Context(), healthCheckTimeout)
+		defer cancel()
+		status := registry.HealthCheck(ctx)
+		for _, s := range status {
+			if s != "ok" {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				break
+			}
+		}
+		writeJSON(w, status)
+	})
+
 	mux.HandleFunc("/connectors", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
-		writeJSON(w, registry.Available())
+		matched, err := filterConnectors(registry.Available(), r.URL.Query())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeJSONTagged(w, r, matched)
+	})
+
+	mux.HandleFunc("/connectors/compatibility", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		writeJSON(w, registry.Compatibility(strictModes))
+	})
+
+	mux.HandleFunc("/connectors/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/connectors/"), "/")
+		if len(parts) > 2 || parts[0] == "" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		name := parts[0]
+		if len(parts) == 1 {
+			if r.Method != http.MethodGet {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			info, err := lookupConnector(registry, name, connectors.ConnectorType(r.URL.Query().Get("type")))
+			if err != nil {
+				http.Error(w, err.Error(), errorStatus(err, http.StatusBadRequest))
+				return
+			}
+			required := []string{}
+			for _, f := range info.Config {
+				if f.Required {
+					required = append(required, f.Name)
+				}
+			}
+			writeJSONTagged(w, r, struct {
+				connectors.Connector
+				RequiredConfig []string `json:"requiredConfig"`
+			}{info, required})
+			return
+		}
+
+		switch parts[1] {
+		case "validate":
+			if r.Method != http.MethodPost {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			var req struct {
+				Type   connectors.ConnectorType `json:"type"`
+				Config map[string]string        `json:"config"`
+			}
+			if err := decodeJSON(w, r, maxBodyBytes, &req); err != nil {
+				http.Error(w, err.Error(), decodeStatus(err))
+				return
+			}
+			if err := registry.Validate(name, req.Type, req.Config); err != nil {
+				http.Error(w, err.Error(), errorStatus(err, http.StatusBadRequest))
+				return
+			}
+			writeJSON(w, map[string]string{"status": "ok"})
+		case "sample", "schema":
+			if r.Method != http.MethodPost {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			limit, err := queryInt(r.URL.Query(), "limit")
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if limit == 0 {
+				limit = defaultSampleSize
+			}
+			var req struct {
+				Config map[string]string `json:"config"`
+			}
+			if err := decodeJSON(w, r, maxBodyBytes, &req); err != nil {
+				http.Error(w, err.Error(), decodeStatus(err))
+				return
+			}
+			sample, err := registry.Sample(r.Context(), name, req.Config, limit)
+			if err != nil {
+				http.Error(w, err.Error(), errorStatus(err, http.StatusBadRequest))
+				return
+			}
+			if parts[1] == "schema" {
+				writeJSON(w, map[string]any{
+					"sampleSize": len(sample),
+					"fields":     connectors.InferSchema(sample),
+				})
+				return
+			}
+			writeJSON(w, sample)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
 	})
 
 	mux.HandleFunc("/pipelines", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		switch r.Method {
 		case http.MethodGet:
-			writeJSON(w, svc.List())
+			q := r.URL.Query()
+			opts := pipeline.ListOptions{SourceType: q.Get("sourceType")}
+			var err error
+			if opts.Limit, err = queryInt(q, "limit"); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if opts.Offset, err = queryInt(q, "offset"); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			configs, total, err := svc.List(opts)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("X-Total-Count", strconv.Itoa(total))
+			writeJSON(w, configs)
 		case http.MethodPost:
 			var cfg pipeline.Config
-			if err := json.NewDecoder(r.Body).Decode(&cfg); err != nil {
-				http.Error(w, err.Error(), http.StatusBadRequest)
+			if err := decodeJSON(w, r, maxBodyBytes, &cfg); err != nil {
+				http.Error(w, err.Error(), decodeStatus(err))
 				return
 			}
 			if err := svc.Create(cfg); err != nil {
-				http.Error(w, err.Error(), http.StatusBadRequest)
+				http.Error(w, err.Error(), errorStatus(err, http.StatusInternalServerError))
 				return
 			}
 			writeJSON(w, map[string]string{"status": "created"})
@@ -49,34 +377,587 @@ func main() {
 		}
 	})
 
-	mux.HandleFunc("/pipelines/", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/pipelines/batch", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		if r.Method != http.MethodPost {
 			w.WriteHeader(http.StatusMethodNotAllowed)
 			return
 		}
+		var cfgs []pipeline.Config
+		if err := decodeJSON(w, r, maxBodyBytes, &cfgs); err != nil {
+			http.Error(w, err.Error(), decodeStatus(err))
+			return
+		}
+		type itemResult struct {
+			Name   string `json:"name"`
+			Status string `json:"status"`
+			Code   int    `json:"code"`
+			Error  string `json:"error,omitempty"`
+		}
+		// results line up with the request array so clients can match them by index
+		results := make([]itemResult, len(cfgs))
+		for i, cfg := range cfgs {
+			results[i] = itemResult{Name: cfg.Name, Status: "created", Code: http.StatusCreated}
+			if err := svc.Create(cfg); err != nil {
+				results[i].Status = "failed"
+				results[i].Code = errorStatus(err, http.StatusInternalServerError)
+				results[i].Error = err.Error()
+			}
+		}
+		writeJSON(w, results)
+	})
+
+	mux.HandleFunc("/pipelines/diff", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		a, b := r.URL.Query().Get("a"), r.URL.Query().Get("b")
+		if a == "" || b == "" {
+			http.Error(w, "query parameters a and b must name the pipelines to compare", http.StatusBadRequest)
+			return
+		}
+		diffs, err := svc.Diff(a, b)
+		if err != nil {
+			http.Error(w, err.Error(), errorStatus(err, http.StatusInternalServerError))
+			return
+		}
+		writeJSON(w, map[string]any{"a": a, "b": b, "identical": len(diffs) == 0, "differences": diffs})
+	})
+
+	mux.HandleFunc("/pipelines/export", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		// secrets are redacted unless an admin key asks for them, since the export is a GET viewers may make
+		list := func() ([]pipeline.Config, error) {
+			configs, _, err := svc.List(pipeline.ListOptions{})
+			return configs, err
+		}
+		if r.URL.Query().Get("secrets") == "true" {
+			if c, ok := requestCaller(r); !ok || c.role != roleAdmin {
+				http.Error(w, "exporting secrets requires an admin API key", http.StatusForbidden)
+				return
+			}
+			list = svc.Export
+		}
+		configs, err := list()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, exportDocument{Version: exportVersion, ExportedAt: time.Now().UTC(), Pipelines: configs})
+	})
+
+	mux.HandleFunc("/pipelines/import", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		var doc exportDocument
+		if err := decodeJSON(w, r, maxBodyBytes, &doc); err != nil {
+			http.Error(w, err.Error(), decodeStatus(err))
+			return
+		}
+		if doc.Version != exportVersion {
+			http.Error(w, fmt.Sprintf("unsupported export version %d, expected %d", doc.Version, exportVersion), http.StatusBadRequest)
+			return
+		}
+		overwrite := r.URL.Query().Get("overwrite") == "true"
+		type importResult struct {
+			Name   string `json:"name"`
+			Status string `json:"status"`
+			Error  string `json:"error,omitempty"`
+		}
+		results := make([]importResult, len(doc.Pipelines))
+		for i, cfg := range doc.Pipelines {
+			results[i] = importResult{Name: cfg.Name, Status: "created"}
+			// existing pipelines are updated rather than created so redacted secrets keep their stored values
+			var err error
+			if _, exists := svc.Get(cfg.Name); !exists {
+				err = svc.Create(cfg)
+			} else if overwrite {
+				results[i].Status = "updated"
+				err = svc.Update(cfg)
+			} else {
+				results[i].Status = "skipped"
+				results[i].Error = pipeline.ErrPipelineExists.Error()
+				continue
+			}
+			if err != nil {
+				results[i].Status = "failed"
+				results[i].Error = err.Error()
+			}
+		}
+		writeJSON(w, results)
+	})
+
+	mux.HandleFunc("/pipelines/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
 		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/pipelines/"), "/")
-		if len(parts) != 2 || parts[1] != "run" {
+		name := parts[0]
+		if name == "" {
 			w.WriteHeader(http.StatusNotFound)
 			return
 		}
-		name := parts[0]
-		res := svc.Run(r.Context(), name)
-		writeJSON(w, res)
+
+		switch {
+		case len(parts) == 1:
+			switch r.Method {
+			case http.MethodGet:
+				cfg, ok := svc.Get(name)
+				if !ok {
+					http.Error(w, "pipeline not found", http.StatusNotFound)
+					return
+				}
+				writeJSON(w, cfg)
+			case http.MethodPut:
+				var cfg pipeline.Config
+				if err := decodeJSON(w, r, maxBodyBytes, &cfg); err != nil {
+					http.Error(w, err.Error(), decodeStatus(err))
+					return
+				}
+				if cfg.Name == "" {
+					cfg.Name = name
+				}
+				if cfg.Name != name {
+					http.Error(w, "pipeline name cannot be changed", http.StatusBadRequest)
+					return
+				}
+				if err := svc.Update(cfg); err != nil {
+					http.Error(w, err.Error(), errorStatus(err, http.StatusInternalServerError))
+					return
+				}
+				writeJSON(w, map[string]string{"status": "updated"})
+			case http.MethodDelete:
+				if err := svc.Delete(name); err != nil {
+					http.Error(w, err.Error(), errorStatus(err, http.StatusInternalServerError))
+					return
+				}
+				writeJSON(w, map[string]string{"status": "deleted"})
+			default:
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+		case len(parts) == 2 && parts[1] == "run":
+			if r.Method != http.MethodPost {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			if r.URL.Query().Get("dryRun") == "true" {
+				writeJSON(w, svc.DryRun(r.Context(), name))
+				return
+			}
+			if svc.IsRunning(name) {
+				http.Error(w, pipeline.ErrAlreadyRunning.Error(), http.StatusConflict)
+				return
+			}
+			if r.URL.Query().Get("async") == "true" {
+				id, position, err := svc.RunAsync(r.Context(), name)
+				if err != nil {
+					if errors.Is(err, pipeline.ErrServerBusy) {
+						w.Header().Set("Retry-After", strconv.Itoa(int(busyRetryAfter.Seconds())))
+					}
+					http.Error(w, err.Error(), errorStatus(err, http.StatusInternalServerError))
+					return
+				}
+				w.WriteHeader(http.StatusAccepted)
+				writeJSON(w, map[string]any{"jobId": id, "queuePosition": position})
+				return
+			}
+			res := svc.Run(r.Context(), name)
+			// a run that never started, or was refused, keeps its result body but not a 200
+			if status := errorStatus(res.Err, http.StatusOK); status != http.StatusOK {
+				if errors.Is(res.Err, pipeline.ErrServerBusy) {
+					w.Header().Set("Retry-After", strconv.Itoa(int(busyRetryAfter.Seconds())))
+				}
+				w.WriteHeader(status)
+			}
+			writeJSON(w, res)
+		case len(parts) == 3 && parts[1] == "run" && parts[2] == "stream":
+			if r.Method != http.MethodGet {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			if svc.IsRunning(name) {
+				http.Error(w, pipeline.ErrAlreadyRunning.Error(), http.StatusConflict)
+				return
+			}
+			if svc.Busy() {
+				w.Header().Set("Retry-After", strconv.Itoa(int(busyRetryAfter.Seconds())))
+				http.Error(w, pipeline.ErrServerBusy.Error(), http.StatusServiceUnavailable)
+				return
+			}
+			streamRun(w, r, svc, name)
+		case len(parts) == 2 && parts[1] == "clone":
+			if r.Method != http.MethodPost {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			var req struct {
+				Name         string            `json:"name"`
+				SourceConfig map[string]string `json:"sourceConfig"`
+				DestConfig   map[string]string `json:"destConfig"`
+			}
+			if err := decodeJSON(w, r, maxBodyBytes, &req); err != nil {
+				http.Error(w, err.Error(), decodeStatus(err))
+				return
+			}
+			opts := pipeline.CloneOptions{Name: req.Name, SourceConfig: req.SourceConfig, DestConfig: req.DestConfig}
+			if err := svc.Clone(name, opts); err != nil {
+				http.Error(w, err.Error(), errorStatus(err, http.StatusInternalServerError))
+				return
+			}
+			writeJSON(w, map[string]string{"status": "created"})
+		case len(parts) == 2 && (parts[1] == "pause" || parts[1] == "resume"):
+			if r.Method != http.MethodPost {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			if err := svc.SetEnabled(name, parts[1] == "resume"); err != nil {
+				http.Error(w, err.Error(), errorStatus(err, http.StatusInternalServerError))
+				return
+			}
+			writeJSON(w, map[string]bool{"enabled": parts[1] == "resume"})
+		case len(parts) == 2 && parts[1] == "cancel":
+			if r.Method != http.MethodPost {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			if err := svc.Cancel(name); err != nil {
+				http.Error(w, err.Error(), errorStatus(err, http.StatusInternalServerError))
+				return
+			}
+			writeJSON(w, map[string]string{"status": "cancelling"})
+		case len(parts) == 2 && parts[1] == "progress":
+			if r.Method != http.MethodGet {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			if _, ok := svc.Get(name); !ok {
+				http.Error(w, "pipeline not found", http.StatusNotFound)
+				return
+			}
+			records, running := svc.Progress(name)
+			writeJSON(w, map[string]any{"records": records, "running": running})
+		case len(parts) == 2 && parts[1] == "runs":
+			if r.Method != http.MethodGet {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			if _, ok := svc.Get(name); !ok {
+				http.Error(w, "pipeline not found", http.StatusNotFound)
+				return
+			}
+			q := r.URL.Query()
+			opts := pipeline.HistoryOptions{Status: q.Get("status")}
+			if raw := q.Get("since"); raw != "" {
+				since, err := time.Parse(time.RFC3339, raw)
+				if err != nil {
+					http.Error(w, fmt.Sprintf("query parameter since must be an RFC 3339 timestamp such as 2024-01-02T15:04:05Z, got %q", raw), http.StatusBadRequest)
+					return
+				}
+				opts.Since = since
+			}
+			if opts.Status != "" && opts.Status != pipeline.StatusSucceeded && opts.Status != pipeline.StatusFailed {
+				http.Error(w, fmt.Sprintf("query parameter status must be %s or %s", pipeline.StatusSucceeded, pipeline.StatusFailed), http.StatusBadRequest)
+				return
+			}
+			writeJSON(w, svc.History(name, opts))
+		case len(parts) == 2 && parts[1] == "cursor":
+			if _, ok := svc.Get(name); !ok {
+				http.Error(w, "pipeline not found", http.StatusNotFound)
+				return
+			}
+			switch r.Method {
+			case http.MethodGet:
+				var offset *int64
+				if cursor, ok := svc.Cursor(name); ok {
+					offset = &cursor
+				}
+				writeJSON(w, map[string]any{"offset": offset})
+			case http.MethodDelete:
+				svc.ResetCursor(name)
+				writeJSON(w, map[string]string{"status": "reset"})
+			default:
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
 	})
 
-	addr := ":8080"
-	if port := os.Getenv("PORT"); port != "" {
-		addr = ":" + port
+	mux.HandleFunc("/schedules", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		schedules, err := svc.Schedules()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, map[string]any{"paused": svc.SchedulerPaused(), "schedules": schedules})
+	})
+
+	mux.HandleFunc("/schedules/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		switch strings.TrimPrefix(r.URL.Path, "/schedules/") {
+		case "pause":
+			svc.PauseScheduler()
+		case "resume":
+			svc.ResumeScheduler()
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		writeJSON(w, map[string]bool{"paused": svc.SchedulerPaused()})
+	})
+
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		if err := svc.Metrics().WritePrometheus(w); err != nil {
+			slog.Error("write metrics", "error", err)
+		}
+	})
+
+	mux.HandleFunc("/queue", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		writeJSON(w, svc.QueueStatus())
+	})
+
+	mux.HandleFunc("/stats", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		stats, err := svc.Stats()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, stats)
+	})
+
+	mux.HandleFunc("/breakers", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		writeJSON(w, svc.Breakers())
+	})
+
+	mux.HandleFunc("/jobs/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		id := strings.TrimPrefix(r.URL.Path, "/jobs/")
+		if id == "" || strings.Contains(id, "/") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			res, ok := svc.JobStatus(id)
+			if !ok {
+				http.Error(w, "job not found", http.StatusNotFound)
+				return
+			}
+			writeJSON(w, res)
+		case http.MethodDelete:
+			if err := svc.CancelJob(id); err != nil {
+				http.Error(w, err.Error(), http.StatusConflict)
+				return
+			}
+			writeJSON(w, map[string]string{"status": "cancelling"})
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+	return mux
+}
+
+// exportDocument is the backup format of GET /pipelines/export and POST /pipelines/import.
+type exportDocument struct {
+	Version    int               `json:"version"`
+	ExportedAt time.Time         `json:"exportedAt"`
+	Pipelines  []pipeline.Config `json:"pipelines"`
+}
+
+// splitList parses a comma-separated env value, ignoring blank entries.
+func splitList(raw string) []string {
+	var result []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
 	}
-	srv := &http.Server{
-		Addr:              addr,
-		Handler:           mux,
-		ReadHeaderTimeout: 5 * time.Second,
+	return result
+}
+
+// queryInt parses an optional non-negative integer query parameter, returning 0 when absent.
+func queryInt(q url.Values, key string) (int, error) {
+	raw := q.Get(key)
+	if raw == "" {
+		return 0, nil
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("query parameter %s must be a non-negative integer", key)
+	}
+	return v, nil
+}
+
+// connectorFilters are the query parameters accepted by GET /connectors.
+var connectorFilters = []string{"type", "mode", "supportsDDL"}
+
+// filterConnectors returns the connectors matching every filter in q. Unknown query parameters
+// are rejected rather than ignored, so a misspelt filter does not silently list everything.
+func filterConnectors(all []connectors.Connector, q url.Values) ([]connectors.Connector, error) {
+	for key := range q {
+		if !slices.Contains(connectorFilters, key) {
+			return nil, fmt.Errorf("unknown filter %q; supported filters are %s", key, strings.Join(connectorFilters, ", "))
+		}
+	}
+	typ := connectors.ConnectorType(q.Get("type"))
+	if typ != "" && typ != connectors.SourceType && typ != connectors.DestinationType {
+		return nil, fmt.Errorf("type must be %s or %s", connectors.SourceType, connectors.DestinationType)
+	}
+	mode := connectors.Mode(q.Get("mode"))
+	if mode != "" && mode != connectors.ModeStreaming && mode != connectors.ModeBatch {
+		return nil, fmt.Errorf("mode must be %s or %s", connectors.ModeStreaming, connectors.ModeBatch)
 	}
+	var ddl *bool
+	if raw := q.Get("supportsDDL"); raw != "" {
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, errors.New("supportsDDL must be true or false")
+		}
+		ddl = &v
+	}
+
+	matched := []connectors.Connector{}
+	for _, c := range all {
+		if (typ == "" || c.Type == typ) && (mode == "" || c.Mode == mode) && (ddl == nil || c.SupportsDDL == *ddl) {
+			matched = append(matched, c)
+		}
+	}
+	return matched, nil
+}
 
-	log.Printf("server listening on %s", addr)
-	log.Fatal(srv.ListenAndServe())
+// lookupConnector finds the named connector of type typ. Without a type the name must belong to
+// exactly one kind of connector; a name shared by a source and a destination is ambiguous.
+func lookupConnector(reg *connectors.Registry, name string, typ connectors.ConnectorType) (connectors.Connector, error) {
+	switch typ {
+	case connectors.SourceType, connectors.DestinationType:
+		return reg.Lookup(name, typ)
+	case "":
+	default:
+		return connectors.Connector{}, fmt.Errorf("type must be %s or %s", connectors.SourceType, connectors.DestinationType)
+	}
+	src, srcErr := reg.Lookup(name, connectors.SourceType)
+	dst, dstErr := reg.Lookup(name, connectors.DestinationType)
+	switch {
+	case srcErr == nil && dstErr == nil:
+		return connectors.Connector{}, fmt.Errorf("%s is both a source and a destination; pass ?type=%s or ?type=%s",
+			name, connectors.SourceType, connectors.DestinationType)
+	case srcErr == nil:
+		return src, nil
+	case dstErr == nil:
+		return dst, nil
+	default:
+		return connectors.Connector{}, fmt.Errorf("%w; %w", srcErr, dstErr)
+	}
+}
+
+// decodeJSON strictly decodes the request body into v, reading at most limit bytes. Unknown fields
+// are rejected, and errors name the offending field where possible.
+func decodeJSON(w http.ResponseWriter, r *http.Request, limit int64, v any) error {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, limit))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(v)
+	var (
+		typeErr   *json.UnmarshalTypeError
+		syntaxErr *json.SyntaxError
+	)
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &typeErr):
+		field := cmp.Or(typeErr.Field, "request body")
+		return fmt.Errorf("%s must be %s", field, jsonKind(typeErr.Type))
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return fmt.Errorf("request body is not valid JSON: %w", err)
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// the decoder has no typed error for unknown fields
+		return errors.New(strings.TrimPrefix(err.Error(), "json: "))
+	default:
+		return err
+	}
+}
+
+// jsonKind describes the JSON value expected for a Go type.
+func jsonKind(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	default:
+		return "a " + t.String()
+	}
+}
+
+// decodeStatus is the status for a decodeJSON error: 413 for an oversized body, otherwise 400.
+func decodeStatus(err error) int {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
+
+// errorStatus maps the sentinel errors of the pipeline and connectors packages to a status code,
+// returning fallback for anything else. Validation is checked first so an unknown connector named in
+// a pipeline definition is a bad request rather than a missing resource.
+func errorStatus(err error, fallback int) int {
+	switch {
+	case errors.Is(err, pipeline.ErrValidation):
+		return http.StatusBadRequest
+	case errors.Is(err, pipeline.ErrPipelineNotFound),
+		errors.Is(err, connectors.ErrUnknownSource),
+		errors.Is(err, connectors.ErrUnknownDestination):
+		return http.StatusNotFound
+	case errors.Is(err, pipeline.ErrPipelineExists),
+		errors.Is(err, pipeline.ErrAlreadyRunning),
+		errors.Is(err, pipeline.ErrNotRunning):
+		return http.StatusConflict
+	case errors.Is(err, pipeline.ErrServerBusy),
+		errors.Is(err, pipeline.ErrCircuitOpen):
+		return http.StatusServiceUnavailable
+	default:
+		return fallback
+	}
 }
 
 func writeJSON(w http.ResponseWriter, payload any) {
@@ -84,3 +965,33 @@ func writeJSON(w http.ResponseWriter, payload any) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 }
+
+// writeJSONTagged writes payload like writeJSON with an ETag derived from the encoded body, and
+// answers 304 instead when the request's If-None-Match already names it. The tag is weak so it
+// still matches once the body is gzip-compressed.
+func writeJSONTagged(w http.ResponseWriter, r *http.Request, payload any) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	sum := sha256.Sum256(body)
+	tag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+	w.Header().Set("ETag", tag)
+	if etagMatches(r.Header.Get("If-None-Match"), tag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Write(append(body, '\n'))
+}
+
+// etagMatches reports whether an If-None-Match header lists tag or is "*", comparing weakly.
+func etagMatches(header, tag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(tag, "W/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,465 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"job-hunt/backend/internal/connectors"
+	"job-hunt/backend/internal/pipeline"
+)
+
+// newTestServer serves the API over a fresh registry and in-memory store, behind the middleware
+// that needs no configuration and requireAPIKey with keys, nil leaving the API open.
+func newTestServer(t *testing.T, keys map[string]string) (*httptest.Server, *pipeline.Service, *connectors.Registry) {
+	t.Helper()
+	reg, err := connectors.NewRegistry()
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+	svc := pipeline.NewService(reg, pipeline.NewMemoryStore())
+	var ready atomic.Bool
+	ready.Store(true)
+	srv := httptest.NewServer(tagRequests(logRequests(recoverPanics(requireAPIKey(keys, newMux(reg, svc, defaultMaxBodyBytes, false, &ready))))))
+	t.Cleanup(srv.Close)
+	return srv, svc, reg
+}
+
+// do sends a request with an optional JSON body and returns the response and its body.
+func do(t *testing.T, srv *httptest.Server, method, path, body string) (*http.Response, string) {
+	t.Helper()
+	return doAs(t, srv, "", method, path, body)
+}
+
+// doAs is do with token, if set, sent as the bearer API key.
+func doAs(t *testing.T, srv *httptest.Server, token, method, path, body string) (*http.Response, string) {
+	t.Helper()
+	req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, string(data)
+}
+
+// testPipeline is a fast pipeline from the simulated HTTP source into the null destination.
+func testPipeline(name string) pipeline.Config {
+	return pipeline.Config{
+		Name:         name,
+		SourceType:   "http",
+		SourceConfig: map[string]string{"url": "https://api.example.com/items", "recordCount": "3", "pacingMs": "0"},
+		DestType:     "null",
+		DestConfig:   map[string]string{},
+	}
+}
+
+// panickingSource is the http source with an Extract that panics.
+type panickingSource struct{ connectors.HTTPSource }
+
+func (s *panickingSource) Info() connectors.Connector {
+	info := s.HTTPSource.Info()
+	info.Name = "panicking"
+	return info
+}
+
+func (s *panickingSource) Extract(context.Context, map[string]string) (<-chan map[string]any, error) {
+	panic("connector bug")
+}
+
+func TestPanickingConnector(t *testing.T) {
+	srv, svc, reg := newTestServer(t, nil)
+	if err := reg.RegisterSource(&panickingSource{}); err != nil {
+		t.Fatalf("RegisterSource: %v", err)
+	}
+	cfg := testPipeline("panicking")
+	cfg.SourceType = "panicking"
+	for _, c := range []pipeline.Config{cfg, testPipeline("healthy")} {
+		if err := svc.Create(c); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	// sampling calls Extract on the handler's goroutine, so the middleware catches the panic
+	resp, body := do(t, srv, http.MethodPost, "/connectors/panicking/sample", `{"config": {"url": "https://api.example.com/items"}}`)
+	if resp.StatusCode != http.StatusInternalServerError || strings.TrimSpace(body) != "internal server error" {
+		t.Fatalf("sample = %d %q, want 500 with a generic message", resp.StatusCode, body)
+	}
+
+	// a run recovers the panic itself and reports a failed result
+	resp, body = do(t, srv, http.MethodPost, "/pipelines/panicking/run", "")
+	var res pipeline.Result
+	if err := json.Unmarshal([]byte(body), &res); err != nil {
+		t.Fatalf("run = %d %q: %v", resp.StatusCode, body, err)
+	}
+	if res.Status != pipeline.StatusFailed || !strings.Contains(res.Error, "panicked: connector bug") {
+		t.Fatalf("run = %s %q, want failed with the panic", res.Status, res.Error)
+	}
+
+	// the server is still up and runs other pipelines
+	if resp, _ := do(t, srv, http.MethodGet, "/health", ""); resp.StatusCode != http.StatusOK {
+		t.Fatalf("health = %d after the panics", resp.StatusCode)
+	}
+	_, body = do(t, srv, http.MethodPost, "/pipelines/healthy/run", "")
+	if err := json.Unmarshal([]byte(body), &res); err != nil || res.Status != pipeline.StatusSucceeded {
+		t.Fatalf("healthy run = %q", body)
+	}
+}
+
+func TestPipelineSecretsRedacted(t *testing.T) {
+	srv, _, _ := newTestServer(t, nil)
+	const secret = "hunter2-not-for-responses"
+	resp, body := do(t, srv, http.MethodPost, "/pipelines", `{
+		"name": "orders",
+		"sourceType": "mysql",
+		"sourceConfig": {"host": "db.internal", "port": "3306", "user": "etl", "password": "`+secret+`", "database": "shop"},
+		"destType": "postgres",
+		"destConfig": {"host": "warehouse", "port": "5432", "user": "etl", "password": "`+secret+`", "database": "analytics"},
+		"destinations": [{"type": "postgres", "config": {"host": "replica", "port": "5432", "user": "etl", "password": "`+secret+`", "database": "analytics"}}]
+	}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("create = %d %q", resp.StatusCode, body)
+	}
+
+	for _, path := range []string{"/pipelines", "/pipelines/orders", "/pipelines/export"} {
+		resp, body := do(t, srv, http.MethodGet, path, "")
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("GET %s = %d %q", path, resp.StatusCode, body)
+		}
+		if strings.Contains(body, secret) {
+			t.Errorf("GET %s leaks the password: %s", path, body)
+		}
+		if n := strings.Count(body, `"password":"`+pipeline.Redacted+`"`); n != 3 {
+			t.Errorf("GET %s has %d redacted passwords, want 3: %s", path, n, body)
+		}
+	}
+	// without API keys there is no admin to export secrets to
+	if resp, _ := do(t, srv, http.MethodGet, "/pipelines/export?secrets=true", ""); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("export with secrets on an open API = %d, want 403", resp.StatusCode)
+	}
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	keys := map[string]string{"root": roleAdmin, "peek": roleViewer}
+	src, _, _ := newTestServer(t, keys)
+	const secret = "hunter2-round-trip"
+	created, _ := json.Marshal(pipeline.Config{
+		Name:         "orders",
+		SourceType:   "mysql",
+		SourceConfig: map[string]string{"host": "db.internal", "port": "3306", "user": "etl", "password": secret, "database": "shop"},
+		DestType:     "postgres",
+		DestConfig:   map[string]string{"host": "warehouse", "port": "5432", "user": "etl", "password": "${PIPELINE_SECRET_PG_PASSWORD}", "database": "analytics"},
+	})
+	t.Setenv("PIPELINE_SECRET_PG_PASSWORD", "from-env")
+	if resp, body := doAs(t, src, "root", http.MethodPost, "/pipelines", string(created)); resp.StatusCode != http.StatusOK {
+		t.Fatalf("create = %d %q", resp.StatusCode, body)
+	}
+
+	// only an admin key may export secrets
+	if resp, _ := doAs(t, src, "peek", http.MethodGet, "/pipelines/export?secrets=true", ""); resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("viewer export with secrets = %d, want 403", resp.StatusCode)
+	}
+	_, full := doAs(t, src, "root", http.MethodGet, "/pipelines/export?secrets=true", "")
+	_, redacted := doAs(t, src, "peek", http.MethodGet, "/pipelines/export", "")
+	if !strings.Contains(full, secret) || strings.Contains(redacted, secret) {
+		t.Fatalf("full export %q, redacted export %q", full, redacted)
+	}
+
+	importResults := func(srv *httptest.Server, query, doc string) string {
+		t.Helper()
+		resp, body := doAs(t, srv, "root", http.MethodPost, "/pipelines/import"+query, doc)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("import = %d %q", resp.StatusCode, body)
+		}
+		var results []struct{ Status, Error string }
+		if err := json.Unmarshal([]byte(body), &results); err != nil || len(results) != 1 {
+			t.Fatalf("import results %q: %v", body, err)
+		}
+		return results[0].Status + " " + results[0].Error
+	}
+
+	// the full export restores onto a fresh server, secrets and env references intact
+	dst, _, _ := newTestServer(t, keys)
+	if got := importResults(dst, "", full); got != "created " {
+		t.Fatalf("import of the full export = %q", got)
+	}
+	_, restored := doAs(t, dst, "root", http.MethodGet, "/pipelines/export?secrets=true", "")
+	if got, want := exportedPipelines(t, restored), exportedPipelines(t, full); got != want {
+		t.Fatalf("restored pipelines\n%s\nwant\n%s", got, want)
+	}
+
+	// the redacted export overwrites in place, keeping the stored secrets
+	if got := importResults(dst, "?overwrite=true", redacted); got != "updated " {
+		t.Fatalf("overwriting import of the redacted export = %q", got)
+	}
+	_, restored = doAs(t, dst, "root", http.MethodGet, "/pipelines/export?secrets=true", "")
+	if got, want := exportedPipelines(t, restored), exportedPipelines(t, full); got != want {
+		t.Fatalf("pipelines after overwrite\n%s\nwant\n%s", got, want)
+	}
+
+	// but cannot create pipelines on a server that never had the secrets
+	fresh, _, _ := newTestServer(t, keys)
+	if got := importResults(fresh, "", redacted); !strings.HasPrefix(got, "failed ") || !strings.Contains(got, "is redacted") {
+		t.Fatalf("import of the redacted export into a fresh server = %q", got)
+	}
+}
+
+// exportedPipelines returns the pipelines of an export document re-encoded, for comparison.
+func exportedPipelines(t *testing.T, body string) string {
+	t.Helper()
+	var doc exportDocument
+	if err := json.Unmarshal([]byte(body), &doc); err != nil {
+		t.Fatalf("export %q: %v", body, err)
+	}
+	data, err := json.Marshal(doc.Pipelines)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestRunShedWhenBusy(t *testing.T) {
+	srv, svc, _ := newTestServer(t, nil)
+	svc.SetMaxConcurrentRuns(1)
+	slow := testPipeline("slow")
+	slow.SourceConfig["recordCount"] = "10000"
+	slow.SourceConfig["pacingMs"] = "5"
+	for _, c := range []pipeline.Config{slow, testPipeline("quick")} {
+		if err := svc.Create(c); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+	id, _, err := svc.RunAsync(context.Background(), "slow")
+	if err != nil {
+		t.Fatalf("RunAsync: %v", err)
+	}
+	defer svc.CancelJob(id)
+
+	resp, body := do(t, srv, http.MethodPost, "/pipelines/quick/run", "")
+	if resp.StatusCode != http.StatusServiceUnavailable || resp.Header.Get("Retry-After") == "" {
+		t.Fatalf("run while busy = %d %q, want 503 with Retry-After", resp.StatusCode, body)
+	}
+	var res pipeline.Result
+	if err := json.Unmarshal([]byte(body), &res); err != nil || res.Error != pipeline.ErrServerBusy.Error() {
+		t.Fatalf("run while busy = %q", body)
+	}
+}
+
+// rejectingDestination is the null destination with a Load that always fails.
+type rejectingDestination struct{ connectors.NullDestination }
+
+func (d *rejectingDestination) Info() connectors.Connector {
+	info := d.NullDestination.Info()
+	info.Name = "rejecting"
+	return info
+}
+
+func (d *rejectingDestination) Load(context.Context, map[string]string, <-chan map[string]any) error {
+	return errors.New("destination rejected the batch")
+}
+
+func TestRunStatus(t *testing.T) {
+	srv, svc, reg := newTestServer(t, nil)
+	if err := reg.RegisterDestination(&rejectingDestination{}); err != nil {
+		t.Fatalf("RegisterDestination: %v", err)
+	}
+	svc.SetCircuitBreaker(1, time.Hour)
+	cfg := testPipeline("rejected")
+	cfg.DestType = "rejecting"
+	for _, c := range []pipeline.Config{cfg, testPipeline("orders")} {
+		if err := svc.Create(c); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	for _, tc := range []struct {
+		path string
+		want int
+		err  string
+	}{
+		{"/pipelines/orders/run", http.StatusOK, ""},
+		{"/pipelines/nope/run", http.StatusNotFound, pipeline.ErrPipelineNotFound.Error()},
+		// a failed load is a run that happened, so it is reported with a 200
+		{"/pipelines/rejected/run", http.StatusOK, "destination rejected the batch"},
+		{"/pipelines/rejected/run", http.StatusServiceUnavailable, "circuit open"},
+	} {
+		resp, body := do(t, srv, http.MethodPost, tc.path, "")
+		var res pipeline.Result
+		if err := json.Unmarshal([]byte(body), &res); err != nil {
+			t.Fatalf("POST %s = %d %q: %v", tc.path, resp.StatusCode, body, err)
+		}
+		if resp.StatusCode != tc.want || !strings.Contains(res.Error, tc.err) {
+			t.Fatalf("POST %s = %d %q, want %d with %q", tc.path, resp.StatusCode, res.Error, tc.want, tc.err)
+		}
+	}
+}
+
+func TestErrorStatus(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want int
+	}{
+		{fmt.Errorf("create: %w", pipeline.ErrValidation), http.StatusBadRequest},
+		{pipeline.ErrPipelineNotFound, http.StatusNotFound},
+		{connectors.ErrUnknownSource, http.StatusNotFound},
+		{pipeline.ErrPipelineExists, http.StatusConflict},
+		{pipeline.ErrAlreadyRunning, http.StatusConflict},
+		{pipeline.ErrNotRunning, http.StatusConflict},
+		{pipeline.ErrServerBusy, http.StatusServiceUnavailable},
+		{fmt.Errorf("%w: destination postgres", pipeline.ErrCircuitOpen), http.StatusServiceUnavailable},
+		{errors.New("anything else"), http.StatusTeapot},
+		{nil, http.StatusTeapot},
+	} {
+		if got := errorStatus(tc.err, http.StatusTeapot); got != tc.want {
+			t.Errorf("errorStatus(%v) = %d, want %d", tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestRunAsyncStatus(t *testing.T) {
+	srv, svc, _ := newTestServer(t, nil)
+	if err := svc.Create(testPipeline("orders")); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if resp, body := do(t, srv, http.MethodPost, "/pipelines/orders/run?async=true", ""); resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("async run = %d %q, want 202", resp.StatusCode, body)
+	}
+	if resp, body := do(t, srv, http.MethodPost, "/pipelines/nope/run?async=true", ""); resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("async run of a missing pipeline = %d %q, want 404", resp.StatusCode, body)
+	}
+}
+
+func TestCancelStatus(t *testing.T) {
+	srv, svc, _ := newTestServer(t, nil)
+	slow := testPipeline("slow")
+	slow.SourceConfig["recordCount"] = "10000"
+	slow.SourceConfig["pacingMs"] = "5"
+	if err := svc.Create(slow); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if resp, body := do(t, srv, http.MethodPost, "/pipelines/nope/cancel", ""); resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("cancel of a missing pipeline = %d %q, want 404", resp.StatusCode, body)
+	}
+	if resp, body := do(t, srv, http.MethodPost, "/pipelines/slow/cancel", ""); resp.StatusCode != http.StatusConflict {
+		t.Fatalf("cancel of an idle pipeline = %d %q, want 409", resp.StatusCode, body)
+	}
+
+	done := make(chan pipeline.Result)
+	go func() { done <- svc.Run(context.Background(), "slow") }()
+	deadline := time.Now().Add(5 * time.Second)
+	for !svc.IsRunning("slow") {
+		if time.Now().After(deadline) {
+			t.Fatal("run did not start")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if resp, body := do(t, srv, http.MethodPost, "/pipelines/slow/cancel", ""); resp.StatusCode != http.StatusOK {
+		t.Fatalf("cancel of a running pipeline = %d %q, want 200", resp.StatusCode, body)
+	}
+	if res := <-done; res.Status != pipeline.StatusFailed || res.Error != "cancelled by user" {
+		t.Fatalf("cancelled run = %s %q", res.Status, res.Error)
+	}
+}
+
+func TestDiffEndpoint(t *testing.T) {
+	srv, svc, _ := newTestServer(t, nil)
+	a, b := testPipeline("a"), testPipeline("b")
+	b.SourceConfig["recordCount"] = "5"
+	for _, cfg := range []pipeline.Config{a, b} {
+		if err := svc.Create(cfg); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	resp, body := do(t, srv, http.MethodGet, "/pipelines/diff?a=a&b=b", "")
+	var got struct {
+		Identical   bool                 `json:"identical"`
+		Differences []pipeline.FieldDiff `json:"differences"`
+	}
+	if err := json.Unmarshal([]byte(body), &got); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("diff = %d %q", resp.StatusCode, body)
+	}
+	if got.Identical || len(got.Differences) != 1 || got.Differences[0].Path != "sourceConfig.recordCount" {
+		t.Fatalf("diff = %+v", got)
+	}
+	if _, body := do(t, srv, http.MethodGet, "/pipelines/diff?a=a&b=a", ""); !strings.Contains(body, `"identical":true`) {
+		t.Fatalf("diff of a pipeline with itself = %q", body)
+	}
+
+	for path, want := range map[string]int{
+		"/pipelines/diff?a=a":        http.StatusBadRequest,
+		"/pipelines/diff?a=a&b=nope": http.StatusNotFound,
+	} {
+		if resp, body := do(t, srv, http.MethodGet, path, ""); resp.StatusCode != want {
+			t.Fatalf("GET %s = %d %q, want %d", path, resp.StatusCode, body, want)
+		}
+	}
+	if resp, _ := do(t, srv, http.MethodPost, "/pipelines/diff?a=a&b=b", ""); resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("POST diff = %d, want 405", resp.StatusCode)
+	}
+}
+
+func TestCloneEndpoint(t *testing.T) {
+	srv, svc, _ := newTestServer(t, nil)
+	if err := svc.Create(testPipeline("orders")); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	for _, tc := range []struct {
+		path, body string
+		want       int
+	}{
+		{"/pipelines/orders/clone", `{"name":"copy","sourceConfig":{"recordCount":"7"}}`, http.StatusOK},
+		{"/pipelines/orders/clone", `{"name":"copy"}`, http.StatusConflict},
+		{"/pipelines/nope/clone", `{"name":"other"}`, http.StatusNotFound},
+		{"/pipelines/orders/clone", `{}`, http.StatusBadRequest},
+		{"/pipelines/orders/clone", `{"name":"x","unknown":1}`, http.StatusBadRequest},
+	} {
+		if resp, body := do(t, srv, http.MethodPost, tc.path, tc.body); resp.StatusCode != tc.want {
+			t.Fatalf("POST %s %s = %d %q, want %d", tc.path, tc.body, resp.StatusCode, body, tc.want)
+		}
+	}
+	if cfg, ok := svc.Get("copy"); !ok || cfg.SourceConfig["recordCount"] != "7" || cfg.SourceConfig["url"] != "https://api.example.com/items" {
+		t.Fatalf("clone = %+v %v", cfg, ok)
+	}
+	if resp, _ := do(t, srv, http.MethodGet, "/pipelines/orders/clone", ""); resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("GET clone = %d, want 405", resp.StatusCode)
+	}
+}
+
+func TestStatsEndpoint(t *testing.T) {
+	srv, svc, _ := newTestServer(t, nil)
+	if err := svc.Create(testPipeline("orders")); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	svc.Run(context.Background(), "orders")
+	resp, body := do(t, srv, http.MethodGet, "/stats", "")
+	var stats pipeline.Stats
+	if err := json.Unmarshal([]byte(body), &stats); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET /stats = %d %q", resp.StatusCode, body)
+	}
+	if stats.Pipelines != 1 || stats.Runs != 1 || stats.Succeeded != 1 || stats.Records != 3 {
+		t.Fatalf("stats = %+v", stats)
+	}
+	if resp, _ := do(t, srv, http.MethodPost, "/stats", ""); resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("POST /stats = %d, want 405", resp.StatusCode)
+	}
+}
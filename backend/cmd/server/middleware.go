@@ -0,0 +1,264 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"job-hunt/backend/internal/pipeline"
+)
+
+// publicPaths are served without an API key so probes and rollout checks keep working when auth
+// is enabled.
+var publicPaths = map[string]bool{
+	"/health":  true,
+	"/ready":   true,
+	"/version": true,
+}
+
+// statusRecorder captures the status code written by a handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController, e.g. for flushing.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// logRequests emits one structured log line per request.
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		slog.InfoContext(r.Context(), "http request",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", rec.status,
+			"durationMs", time.Since(start).Milliseconds(),
+		)
+	})
+}
+
+// recoverPanics turns a panicking handler into a 500 with a generic message, logging the panic and
+// its stack with the request ID, so one bad request cannot take the server down.
+func recoverPanics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+			if p == http.ErrAbortHandler {
+				// the server's own signal to drop the connection quietly
+				panic(p)
+			}
+			slog.ErrorContext(r.Context(), "handler panicked",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"panic", p,
+				"stack", string(debug.Stack()),
+			)
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// maxRequestIDLength bounds a caller-supplied X-Request-ID; longer or non-printable IDs are replaced.
+const maxRequestIDLength = 128
+
+// tagRequests gives every request an ID, taken from X-Request-ID when the caller sends a usable
+// one and generated otherwise. The ID is echoed in the response header and carried in the request
+// context, where requestIDHandler adds it to log lines and pipeline runs record it in their results.
+func tagRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-ID")
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set("X-Request-ID", id)
+		next.ServeHTTP(w, r.WithContext(pipeline.WithRequestID(r.Context(), id)))
+	})
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range []byte(id) {
+		if c <= ' ' || c > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// requestIDHandler adds the request ID from the log call's context to every record.
+type requestIDHandler struct {
+	slog.Handler
+}
+
+func (h requestIDHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := pipeline.RequestID(ctx); id != "" {
+		record.AddAttrs(slog.String("requestId", id))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h requestIDHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return requestIDHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h requestIDHandler) WithGroup(name string) slog.Handler {
+	return requestIDHandler{h.Handler.WithGroup(name)}
+}
+
+// cors allows browser requests from the configured origins. An empty list disables CORS entirely,
+// and "*" allows any origin.
+func cors(origins []string, next http.Handler) http.Handler {
+	if len(origins) == 0 {
+		return next
+	}
+	allowed := map[string]bool{}
+	for _, origin := range origins {
+		allowed[origin] = true
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin != "" && (allowed["*"] || allowed[origin]) {
+			h := w.Header()
+			h.Set("Access-Control-Allow-Origin", origin)
+			h.Add("Vary", "Origin")
+			h.Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+			h.Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID")
+			h.Set("Access-Control-Expose-Headers", "X-Total-Count, Retry-After, ETag, X-Request-ID")
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// API key roles. Viewers may only read; admins may also create, change, and run pipelines.
+const (
+	roleAdmin  = "admin"
+	roleViewer = "viewer"
+)
+
+// parseAPIKeys reads "key:role" entries; a key without a role is an admin key.
+func parseAPIKeys(entries []string) (map[string]string, error) {
+	keys := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		key, role, ok := strings.Cut(entry, ":")
+		if !ok {
+			role = roleAdmin
+		}
+		if key == "" {
+			return nil, fmt.Errorf("API key entry %q has an empty key", entry)
+		}
+		if role != roleAdmin && role != roleViewer {
+			return nil, fmt.Errorf("API key role must be %s or %s, got %q", roleAdmin, roleViewer, role)
+		}
+		keys[key] = role
+	}
+	return keys, nil
+}
+
+// requireAPIKey rejects requests that do not carry one of keys as an "Authorization: Bearer" token,
+// and requests from viewer keys that are not reads. The accepted key is passed on in the request
+// context, see requestCaller. An empty key set disables authentication.
+func requireAPIKey(keys map[string]string, next http.Handler) http.Handler {
+	if len(keys) == 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if publicPaths[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		role := ""
+		if ok {
+			role = keyRole(keys, token)
+		}
+		if role == "" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="job-hunt"`)
+			http.Error(w, "missing or invalid API key", http.StatusUnauthorized)
+			return
+		}
+		if role == roleViewer && !readOnly(r) {
+			http.Error(w, "API key is read-only", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), callerKey{}, caller{key: token, role: role})))
+	})
+}
+
+// caller is the API key requireAPIKey accepted for a request, with its role.
+type caller struct {
+	key  string
+	role string
+}
+
+type callerKey struct{}
+
+// requestCaller returns the caller requireAPIKey authenticated, if any. Without API keys, or on a
+// public path, there is none.
+func requestCaller(r *http.Request) (caller, bool) {
+	c, ok := r.Context().Value(callerKey{}).(caller)
+	return c, ok
+}
+
+// readOnly reports whether a request only reads state, going by its route rather than its method
+// alone. The run stream is a GET but starts a run, while connector validate, sample and schema
+// requests and dry runs are POSTs that change nothing.
+func readOnly(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		return !strings.HasSuffix(r.URL.Path, "/run/stream")
+	case http.MethodPost:
+		if rest, ok := strings.CutPrefix(r.URL.Path, "/connectors/"); ok {
+			parts := strings.Split(rest, "/")
+			return len(parts) == 2 && parts[0] != "" && (parts[1] == "validate" || parts[1] == "sample" || parts[1] == "schema")
+		}
+		if rest, ok := strings.CutPrefix(r.URL.Path, "/pipelines/"); ok {
+			parts := strings.Split(rest, "/")
+			return len(parts) == 2 && parts[0] != "" && parts[1] == "run" && r.URL.Query().Get("dryRun") == "true"
+		}
+	}
+	return false
+}
+
+// keyRole returns the role of token, comparing it against every key in constant time.
+func keyRole(keys map[string]string, token string) string {
+	role := ""
+	for key, r := range keys {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(token)) == 1 {
+			role = r
+		}
+	}
+	return role
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequireAPIKey(t *testing.T) {
+	srv, _, _ := newTestServer(t, map[string]string{"root": roleAdmin})
+	for _, tc := range []struct {
+		name, header, path string
+		want               int
+	}{
+		{"no key", "", "/pipelines", http.StatusUnauthorized},
+		{"wrong key", "Bearer nope", "/pipelines", http.StatusUnauthorized},
+		{"key prefix", "Bearer roo", "/pipelines", http.StatusUnauthorized},
+		{"not bearer", "Basic root", "/pipelines", http.StatusUnauthorized},
+		{"valid key", "Bearer root", "/pipelines", http.StatusOK},
+		{"health", "", "/health", http.StatusOK},
+		{"ready", "", "/ready", http.StatusOK},
+		{"version", "", "/version", http.StatusOK},
+		{"health subpath", "", "/health/connectors", http.StatusUnauthorized},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, srv.URL+tc.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+			resp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tc.want {
+				t.Fatalf("GET %s = %d, want %d", tc.path, resp.StatusCode, tc.want)
+			}
+			if tc.want == http.StatusUnauthorized && resp.Header.Get("WWW-Authenticate") == "" {
+				t.Fatal("401 without a WWW-Authenticate header")
+			}
+		})
+	}
+
+	open, _, _ := newTestServer(t, nil)
+	if resp, _ := do(t, open, http.MethodGet, "/pipelines", ""); resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET with no keys configured = %d, want 200", resp.StatusCode)
+	}
+}
+
+func TestParseAPIKeys(t *testing.T) {
+	keys, err := parseAPIKeys([]string{"root", "ops:admin", "peek:viewer"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"root": roleAdmin, "ops": roleAdmin, "peek": roleViewer}; !maps.Equal(keys, want) {
+		t.Fatalf("parseAPIKeys = %v, want %v", keys, want)
+	}
+	for _, entries := range [][]string{{":viewer"}, {"peek:owner"}, {"peek:"}} {
+		if _, err := parseAPIKeys(entries); err == nil {
+			t.Fatalf("parseAPIKeys(%q) succeeded", entries)
+		}
+	}
+}
+
+func TestViewerRoutes(t *testing.T) {
+	srv, svc, _ := newTestServer(t, map[string]string{"root": roleAdmin, "peek": roleViewer})
+	if err := svc.Create(testPipeline("orders")); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	const source = `{"config":{"url":"https://api.example.com/items","recordCount":"2","pacingMs":"0"}}`
+
+	for _, tc := range []struct {
+		method, path, body string
+		viewer             int
+	}{
+		{http.MethodGet, "/pipelines", "", http.StatusOK},
+		{http.MethodGet, "/pipelines/orders", "", http.StatusOK},
+		{http.MethodGet, "/connectors", "", http.StatusOK},
+		{http.MethodPost, "/connectors/http/validate", `{"type":"source","config":{"url":"https://api.example.com/items"}}`, http.StatusOK},
+		{http.MethodPost, "/connectors/http/sample", source, http.StatusOK},
+		{http.MethodPost, "/connectors/http/schema", source, http.StatusOK},
+		{http.MethodPost, "/pipelines/orders/run?dryRun=true", "", http.StatusOK},
+		{http.MethodPost, "/pipelines/orders/run", "", http.StatusForbidden},
+		{http.MethodPost, "/pipelines/orders/run?dryRun=false", "", http.StatusForbidden},
+		{http.MethodPost, "/pipelines/orders/run?async=true", "", http.StatusForbidden},
+		{http.MethodGet, "/pipelines/orders/run/stream", "", http.StatusForbidden},
+		{http.MethodPost, "/pipelines", `{}`, http.StatusForbidden},
+		{http.MethodPut, "/pipelines/orders", `{}`, http.StatusForbidden},
+		{http.MethodDelete, "/pipelines/orders", "", http.StatusForbidden},
+		{http.MethodPost, "/pipelines/import", `{}`, http.StatusForbidden},
+		{http.MethodPost, "/connectors/http/validate/extra", `{}`, http.StatusForbidden},
+	} {
+		t.Run(tc.method+" "+tc.path, func(t *testing.T) {
+			if resp, body := doAs(t, srv, "peek", tc.method, tc.path, tc.body); resp.StatusCode != tc.viewer {
+				t.Fatalf("viewer got %d %q, want %d", resp.StatusCode, body, tc.viewer)
+			}
+		})
+	}
+
+	// admins reach the routes the viewer was refused
+	if resp, body := doAs(t, srv, "root", http.MethodPost, "/pipelines/orders/run", ""); resp.StatusCode != http.StatusOK {
+		t.Fatalf("admin run = %d %q", resp.StatusCode, body)
+	}
+	if resp, body := doAs(t, srv, "root", http.MethodDelete, "/pipelines/orders", ""); resp.StatusCode != http.StatusOK {
+		t.Fatalf("admin DELETE = %d %q", resp.StatusCode, body)
+	}
+}
+
+func TestCORS(t *testing.T) {
+	// preflights carry no credentials, so they must be answered before the API key check
+	api := requireAPIKey(map[string]string{"root": roleAdmin}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for _, tc := range []struct {
+		name          string
+		origins       []string
+		method        string
+		origin        string
+		preflight     bool
+		allowedOrigin string
+		status        int
+	}{
+		{"preflight", []string{"http://localhost:3000"}, http.MethodOptions, "http://localhost:3000", true, "http://localhost:3000", http.StatusNoContent},
+		{"simple request", []string{"http://localhost:3000"}, http.MethodGet, "http://localhost:3000", false, "http://localhost:3000", http.StatusUnauthorized},
+		{"other origin", []string{"http://localhost:3000"}, http.MethodOptions, "https://evil.example", true, "", http.StatusUnauthorized},
+		{"any origin", []string{"*"}, http.MethodOptions, "https://ui.example", true, "https://ui.example", http.StatusNoContent},
+		{"no origin", []string{"*"}, http.MethodGet, "", false, "", http.StatusUnauthorized},
+		{"plain OPTIONS", []string{"*"}, http.MethodOptions, "https://ui.example", false, "https://ui.example", http.StatusUnauthorized},
+		{"disabled", nil, http.MethodOptions, "http://localhost:3000", true, "", http.StatusUnauthorized},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, "/pipelines", nil)
+			if tc.origin != "" {
+				req.Header.Set("Origin", tc.origin)
+			}
+			if tc.preflight {
+				req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			}
+			rec := httptest.NewRecorder()
+			cors(tc.origins, api).ServeHTTP(rec, req)
+			if rec.Code != tc.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tc.status)
+			}
+			h := rec.Header()
+			if got := h.Get("Access-Control-Allow-Origin"); got != tc.allowedOrigin {
+				t.Fatalf("Access-Control-Allow-Origin = %q, want %q", got, tc.allowedOrigin)
+			}
+			if tc.allowedOrigin != "" && (h.Get("Vary") != "Origin" || h.Get("Access-Control-Allow-Headers") == "" || h.Get("Access-Control-Expose-Headers") == "") {
+				t.Fatalf("allowed origin got headers %v", h)
+			}
+		})
+	}
+}
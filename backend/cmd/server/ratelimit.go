@@ -0,0 +1,141 @@
+package main
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// bucket is one client's token bucket.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// defaultMaxBuckets bounds the clients a rateLimiter tracks at once.
+const defaultMaxBuckets = 10000
+
+// rateLimiter is a token-bucket limiter per client key. Each bucket holds up to burst tokens and
+// refills at rate tokens per second. At most maxBuckets clients are tracked; past that the bucket
+// idle the longest is evicted.
+type rateLimiter struct {
+	rate       float64
+	burst      float64
+	buckets    map[string]*bucket
+	maxBuckets int
+	lastPrune  time.Time
+	mu         sync.Mutex
+}
+
+// newRateLimiter allows perMinute requests per client per minute, with bursts of up to perMinute.
+func newRateLimiter(perMinute int) *rateLimiter {
+	return &rateLimiter{
+		rate:       float64(perMinute) / 60,
+		burst:      float64(perMinute),
+		buckets:    map[string]*bucket{},
+		maxBuckets: defaultMaxBuckets,
+	}
+}
+
+// allow takes a token for key. When none is left it returns how long until one is available.
+func (l *rateLimiter) allow(key string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.prune(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		if len(l.buckets) >= l.maxBuckets {
+			l.evictIdlest()
+		}
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	return false, wait
+}
+
+// prune drops buckets that have refilled completely, at most once a minute, so idle clients do not
+// accumulate.
+func (l *rateLimiter) prune(now time.Time) {
+	if now.Sub(l.lastPrune) < time.Minute {
+		return
+	}
+	l.lastPrune = now
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// evictIdlest drops the bucket that has gone unused the longest. The caller must hold l.mu.
+func (l *rateLimiter) evictIdlest() {
+	idlest := ""
+	for key, b := range l.buckets {
+		if idlest == "" || b.last.Before(l.buckets[idlest].last) {
+			idlest = key
+		}
+	}
+	delete(l.buckets, idlest)
+}
+
+// limitRuns rate-limits requests that start a pipeline run, keyed by API key or, for
+// unauthenticated requests, by remote IP. It must run inside requireAPIKey to see the API key. A
+// limit of zero disables rate limiting.
+func limitRuns(perMinute int, next http.Handler) http.Handler {
+	if perMinute <= 0 {
+		return next
+	}
+	limiter := newRateLimiter(perMinute)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !startsRun(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if ok, wait := limiter.allow(clientKey(r), time.Now()); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			http.Error(w, "run rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// startsRun reports whether the request triggers a pipeline run.
+func startsRun(r *http.Request) bool {
+	if !strings.HasPrefix(r.URL.Path, "/pipelines/") {
+		return false
+	}
+	switch {
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/run"):
+		return r.URL.Query().Get("dryRun") != "true"
+	case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/run/stream"):
+		return true
+	}
+	return false
+}
+
+// clientKey identifies the caller by the API key requireAPIKey accepted, falling back to the
+// remote IP. A bearer token that was never checked is ignored, or every made-up token would get a
+// fresh bucket.
+func clientKey(r *http.Request) string {
+	if c, ok := requestCaller(r); ok {
+		return "key:" + c.key
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
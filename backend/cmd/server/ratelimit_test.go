@@ -0,0 +1,70 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// runStatus sends a run request with an optional bearer token through h and returns the status.
+func runStatus(h http.Handler, token string) int {
+	req := httptest.NewRequest(http.MethodPost, "/pipelines/orders/run", nil)
+	req.RemoteAddr = "203.0.113.7:4711"
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestLimitRunsKeys(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	t.Run("authenticated", func(t *testing.T) {
+		h := requireAPIKey(map[string]string{"alpha": roleAdmin, "beta": roleAdmin}, limitRuns(1, ok))
+		for _, tc := range []struct {
+			token string
+			want  int
+		}{
+			{"alpha", http.StatusOK},
+			{"alpha", http.StatusTooManyRequests},
+			// another key from the same address has its own bucket
+			{"beta", http.StatusOK},
+			{"forged", http.StatusUnauthorized},
+		} {
+			if got := runStatus(h, tc.token); got != tc.want {
+				t.Fatalf("run with %q = %d, want %d", tc.token, got, tc.want)
+			}
+		}
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		// without API keys tokens are never checked, so a fresh token must not buy a fresh bucket
+		h := limitRuns(1, ok)
+		if got := runStatus(h, "first"); got != http.StatusOK {
+			t.Fatalf("first run = %d", got)
+		}
+		if got := runStatus(h, "second"); got != http.StatusTooManyRequests {
+			t.Fatalf("run with a new token = %d, want 429", got)
+		}
+	})
+}
+
+func TestRateLimiterEvictsIdlest(t *testing.T) {
+	l := newRateLimiter(1)
+	l.maxBuckets = 2
+	now := time.Now()
+	l.allow("a", now)
+	l.allow("b", now.Add(time.Second))
+	l.allow("a", now.Add(2*time.Second))
+	l.allow("c", now.Add(3*time.Second))
+
+	if len(l.buckets) != 2 {
+		t.Fatalf("tracking %d buckets, want 2", len(l.buckets))
+	}
+	if _, ok := l.buckets["b"]; ok {
+		t.Fatal("the idlest bucket b was kept")
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"job-hunt/backend/internal/connectors"
+	"job-hunt/backend/internal/pipeline"
+)
+
+// sqliteDriver names the database/sql driver used for the sqlite store. It stays empty unless
+// the binary is built with the sqlite tag, which links the driver in.
+var sqliteDriver string
+
+// openStore builds the pipeline store selected by kind ("file", "memory", or "sqlite").
+func openStore(kind, path string) (pipeline.Store, error) {
+	switch kind {
+	case "", "file":
+		if path == "" {
+			path = "pipelines.json"
+		}
+		return pipeline.NewFileStore(path)
+	case "memory":
+		return pipeline.NewMemoryStore(), nil
+	case "sqlite":
+		if sqliteDriver == "" {
+			return nil, fmt.Errorf("sqlite store requires a binary built with -tags sqlite")
+		}
+		if path == "" {
+			path = "pipelines.db"
+		}
+		db, err := sql.Open(sqliteDriver, path)
+		if err != nil {
+			return nil, fmt.Errorf("open sqlite store: %w", err)
+		}
+		return pipeline.NewSQLiteStore(db)
+	default:
+		return nil, fmt.Errorf("unknown pipeline store %q", kind)
+	}
+}
+
+// encryptStore wraps store so secret config values are encrypted at rest under key, a base64
+// encoded 32-byte key. previous is the key being rotated away from; every value it sealed is
+// resealed under key before the server starts. Without a key the store is returned as is, unless it
+// already holds encrypted values.
+func encryptStore(store pipeline.Store, reg *connectors.Registry, key, previous string) (pipeline.Store, error) {
+	if key == "" {
+		if previous != "" {
+			return nil, errors.New("STORE_PREVIOUS_ENCRYPTION_KEY requires STORE_ENCRYPTION_KEY")
+		}
+		return store, pipeline.CheckUnencrypted(store, reg)
+	}
+	keys := [][]byte{}
+	for _, k := range []struct{ env, value string }{{"STORE_ENCRYPTION_KEY", key}, {"STORE_PREVIOUS_ENCRYPTION_KEY", previous}} {
+		if k.value == "" {
+			continue
+		}
+		raw, err := base64.StdEncoding.DecodeString(k.value)
+		if err != nil || len(raw) != pipeline.EncryptionKeySize {
+			return nil, fmt.Errorf("%s must be %d bytes encoded as base64", k.env, pipeline.EncryptionKeySize)
+		}
+		keys = append(keys, raw)
+	}
+	encrypted, err := pipeline.NewEncryptedStore(store, reg, keys...)
+	if err != nil {
+		return nil, err
+	}
+	if err := encrypted.Reencrypt(); err != nil {
+		return nil, err
+	}
+	return encrypted, nil
+}
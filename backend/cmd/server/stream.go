@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"job-hunt/backend/internal/pipeline"
+)
+
+// progressInterval is how often a streaming run reports its record count.
+const progressInterval = 250 * time.Millisecond
+
+// streamRun starts a run and reports it as Server-Sent Events: "progress" events carry the record
+// count whenever it changes, and a final "result" event carries the full Result. The run is tied to
+// the request context, so a client disconnect cancels it.
+func streamRun(w http.ResponseWriter, r *http.Request, svc *pipeline.Service, name string) {
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		slog.WarnContext(r.Context(), "streaming unsupported", "error", err)
+		return
+	}
+
+	var count atomic.Int64
+	done := make(chan pipeline.Result, 1)
+	go func() {
+		done <- svc.RunWithProgress(r.Context(), name, func(records int) {
+			count.Store(int64(records))
+		})
+	}()
+
+	ticker := time.NewTicker(progressInterval)
+	defer ticker.Stop()
+	last := int64(-1)
+	for {
+		select {
+		case res := <-done:
+			writeEvent(w, rc, "result", res)
+			return
+		case <-ticker.C:
+			if n := count.Load(); n != last {
+				last = n
+				writeEvent(w, rc, "progress", map[string]int64{"records": n})
+			}
+		}
+	}
+}
+
+// writeEvent sends one SSE event with a JSON payload and flushes it to the client.
+func writeEvent(w http.ResponseWriter, rc *http.ResponseController, event string, payload any) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+	rc.Flush()
+}
@@ -1,9 +1,16 @@
 package connectors
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -15,13 +22,104 @@ const (
 	DestinationType ConnectorType = "destination"
 )
 
-// Connector describes shared metadata returned to the UI.
+// Lookup errors returned by the registry, wrapped with the requested name.
+var (
+	ErrUnknownSource      = errors.New("unknown source connector")
+	ErrUnknownDestination = errors.New("unknown destination connector")
+)
+
+// ErrConnectorExists is returned when registering a connector under a name already taken by
+// another connector of the same type.
+var ErrConnectorExists = errors.New("connector already registered")
+
+// StartOffsetKey is the source config key naming the first record offset to extract, which lets
+// incremental runs resume after the records an earlier run already loaded.
+const StartOffsetKey = "startOffset"
+
+// BufferSizeKey is the source config key sizing the channel a source extracts into, letting a
+// fast source get ahead of a slow destination by that many records.
+const BufferSizeKey = "bufferSize"
+
+// Connector describes shared metadata returned to the UI. SupportedTypes lists the inferred field
+// types a destination can store; empty means any.
 type Connector struct {
-	Name        string        `json:"name"`
-	Type        ConnectorType `json:"type"`
-	Description string        `json:"description"`
-	SupportsDDL bool          `json:"supportsDDL"`
-	MaxParallel int           `json:"maxParallel"`
+	Name           string        `json:"name"`
+	Type           ConnectorType `json:"type"`
+	Description    string        `json:"description"`
+	SupportsDDL    bool          `json:"supportsDDL"`
+	MaxParallel    int           `json:"maxParallel"`
+	Mode           Mode          `json:"mode"`
+	Config         []ConfigField `json:"config"`
+	SupportedTypes []string      `json:"supportedTypes,omitempty"`
+	// Aliases are other names the registry resolves to this connector; Available fills them in.
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+// Mode describes how a connector moves records.
+type Mode string
+
+// Connector modes. A streaming source emits an open-ended change feed and a streaming destination
+// accepts records as they arrive; a batch destination commits whole snapshots.
+const (
+	ModeStreaming Mode = "streaming"
+	ModeBatch     Mode = "batch"
+)
+
+// FieldType is the value type of a connector config field.
+type FieldType string
+
+const (
+	FieldString FieldType = "string"
+	FieldInt    FieldType = "int"
+	FieldBool   FieldType = "bool"
+)
+
+// ConfigField describes one config key a connector understands.
+type ConfigField struct {
+	Name     string    `json:"name"`
+	Type     FieldType `json:"type"`
+	Secret   bool      `json:"secret"`
+	Required bool      `json:"required"`
+}
+
+// Shared config schemas reused across connectors.
+var (
+	sqlFields = []ConfigField{
+		{Name: "host", Type: FieldString, Required: true},
+		{Name: "port", Type: FieldInt, Required: true},
+		{Name: "user", Type: FieldString, Required: true},
+		{Name: "password", Type: FieldString, Secret: true, Required: true},
+		{Name: "database", Type: FieldString, Required: true},
+	}
+	icebergFields = []ConfigField{
+		{Name: "catalog", Type: FieldString, Required: true},
+		{Name: "table", Type: FieldString, Required: true},
+		{Name: "warehouse", Type: FieldString, Required: true},
+	}
+	snowflakeFields = []ConfigField{
+		{Name: "account", Type: FieldString, Required: true},
+		{Name: "warehouse", Type: FieldString, Required: true},
+		{Name: "database", Type: FieldString, Required: true},
+		{Name: "schema", Type: FieldString, Required: true},
+		{Name: "user", Type: FieldString, Required: true},
+		{Name: "password", Type: FieldString, Secret: true},
+		{Name: "role", Type: FieldString},
+	}
+	recordCountField = ConfigField{Name: "recordCount", Type: FieldInt}
+	startOffsetField = ConfigField{Name: StartOffsetKey, Type: FieldInt}
+	pacingField      = ConfigField{Name: "pacingMs", Type: FieldInt}
+	bufferField      = ConfigField{Name: BufferSizeKey, Type: FieldInt}
+	batchSizeField   = ConfigField{Name: "batchSize", Type: FieldInt}
+	// columnTypes are the field types a SQL destination can store; nested objects and arrays have
+	// no column type to land in.
+	columnTypes = []string{TypeInt, TypeFloat, TypeString, TypeBool}
+	// sourceFields are the simulation keys every source accepts.
+	sourceFields = []ConfigField{recordCountField, startOffsetField, pacingField, bufferField}
+)
+
+// withFields returns a new schema made of base followed by extra.
+func withFields(base []ConfigField, extra ...ConfigField) []ConfigField {
+	return append(append([]ConfigField{}, base...), extra...)
 }
 
 // Source defines extraction behavior.
@@ -38,17 +136,32 @@ type Destination interface {
 	Load(ctx context.Context, config map[string]string, records <-chan map[string]any) error
 }
 
+// HealthChecker is implemented by connectors that can probe their external dependency.
+// Connectors without it are reported healthy.
+type HealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
 // Registry maintains in-memory connector listings used by the API and UI.
 type Registry struct {
+	mu           sync.RWMutex
 	sources      map[string]Source
 	destinations map[string]Destination
+	// aliases maps each alias to the canonical name it stands for, of a source, a destination, or both
+	aliases map[string]string
+	// available caches the Available listing; registering a connector or an alias clears it
+	available []Connector
 }
 
-// NewRegistry builds the registry with the available connectors.
-func NewRegistry() *Registry {
+// NewRegistry builds the registry with the built-in connectors, rooting the jsonfile source at the
+// directory named by JSONFILE_ROOT. More can be added with RegisterSource and RegisterDestination.
+// Two built-ins of the same type sharing a name is an error rather than one silently replacing the
+// other.
+func NewRegistry() (*Registry, error) {
 	r := &Registry{
 		sources:      map[string]Source{},
 		destinations: map[string]Destination{},
+		aliases:      map[string]string{},
 	}
 
 	for _, src := range []Source{
@@ -56,92 +169,512 @@ func NewRegistry() *Registry {
 		&PostgresSource{},
 		&SQLServerSource{},
 		&IcebergSource{},
+		&S3Source{},
+		&KafkaSource{Partitions: 6},
+		&JSONFileSource{Root: os.Getenv(JSONFileRootEnv)},
+		&HTTPSource{},
 	} {
-		r.sources[src.Info().Name] = src
+		if err := r.RegisterSource(src); err != nil {
+			return nil, err
+		}
 	}
 
 	for _, dst := range []Destination{
 		&MySQLDestination{},
 		&PostgresDestination{},
 		&SQLServerDestination{},
+		&MongoDestination{},
+		&IcebergDestination{},
+		&SnowflakeDestination{},
+		&NullDestination{},
+		&StdoutDestination{Out: os.Stdout},
 	} {
-		r.destinations[dst.Info().Name] = dst
+		if err := r.RegisterDestination(dst); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// RegisterSource adds a source under the name in its Info. A source and a destination may share a
+// name, but two sources may not.
+func (r *Registry) RegisterSource(src Source) error {
+	name := src.Info().Name
+	if name == "" {
+		return errors.New("source connector has no name")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.sources[name]; ok {
+		return fmt.Errorf("%w: source %s", ErrConnectorExists, name)
+	}
+	if target, ok := r.aliases[name]; ok {
+		return fmt.Errorf("%w: %s is an alias of %s", ErrConnectorExists, name, target)
+	}
+	r.sources[name] = src
+	r.available = nil
+	return nil
+}
+
+// RegisterDestination adds a destination under the name in its Info, rejecting a name already taken
+// by another destination.
+func (r *Registry) RegisterDestination(dst Destination) error {
+	name := dst.Info().Name
+	if name == "" {
+		return errors.New("destination connector has no name")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.destinations[name]; ok {
+		return fmt.Errorf("%w: destination %s", ErrConnectorExists, name)
+	}
+	if target, ok := r.aliases[name]; ok {
+		return fmt.Errorf("%w: %s is an alias of %s", ErrConnectorExists, name, target)
+	}
+	r.destinations[name] = dst
+	r.available = nil
+	return nil
+}
+
+// Alias makes alias another name for the source and destination registered as existing, which may
+// itself be an alias. Lookups by alias return the same connector, while Available lists it once
+// under its own name with its aliases attached.
+func (r *Registry) Alias(existing, alias string) error {
+	if alias == "" {
+		return errors.New("connector alias is empty")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if target, ok := r.aliases[existing]; ok {
+		existing = target
+	}
+	_, isSource := r.sources[existing]
+	_, isDestination := r.destinations[existing]
+	if !isSource && !isDestination {
+		return fmt.Errorf("cannot alias unknown connector %s", existing)
+	}
+	if _, ok := r.sources[alias]; ok {
+		return fmt.Errorf("%w: source %s", ErrConnectorExists, alias)
 	}
+	if _, ok := r.destinations[alias]; ok {
+		return fmt.Errorf("%w: destination %s", ErrConnectorExists, alias)
+	}
+	if target, ok := r.aliases[alias]; ok {
+		return fmt.Errorf("%w: %s is an alias of %s", ErrConnectorExists, alias, target)
+	}
+	r.aliases[alias] = existing
+	r.available = nil
+	return nil
+}
 
-	return r
+// canonical returns the registered name alias stands for, or name itself. The caller must hold r.mu.
+func (r *Registry) canonical(name string) string {
+	if target, ok := r.aliases[name]; ok {
+		return target
+	}
+	return name
 }
 
-// Available returns all connectors as combined metadata.
+// Available returns all connectors as combined metadata, sources first and each kind sorted by
+// name, so the listing is stable between calls. Aliases are listed on the connector they name.
+// The listing is built once and reused until another connector or alias is registered.
 func (r *Registry) Available() []Connector {
-	var result []Connector
-	for _, s := range r.sources {
-		result = append(result, s.Info())
+	r.mu.RLock()
+	cached := r.available
+	r.mu.RUnlock()
+	if cached == nil {
+		r.mu.Lock()
+		if r.available == nil {
+			r.available = r.list()
+		}
+		cached = r.available
+		r.mu.Unlock()
+	}
+	// callers may reorder or filter the slice they get without touching the cache
+	return slices.Clone(cached)
+}
+
+// list builds the Available listing. The caller must hold r.mu.
+func (r *Registry) list() []Connector {
+	aliases := map[string][]string{}
+	for alias, target := range r.aliases {
+		aliases[target] = append(aliases[target], alias)
+	}
+	for _, list := range aliases {
+		slices.Sort(list)
 	}
-	for _, d := range r.destinations {
-		result = append(result, d.Info())
+	result := []Connector{}
+	for name, s := range r.sources {
+		info := s.Info()
+		info.Aliases = aliases[name]
+		result = append(result, info)
 	}
+	for name, d := range r.destinations {
+		info := d.Info()
+		info.Aliases = aliases[name]
+		result = append(result, info)
+	}
+	slices.SortFunc(result, func(a, b Connector) int {
+		return cmp.Or(-strings.Compare(string(a.Type), string(b.Type)), strings.Compare(a.Name, b.Name))
+	})
 	return result
 }
 
-// SourceByName fetches a registered source.
+// SourceByName fetches a registered source by its name or an alias.
 func (r *Registry) SourceByName(name string) (Source, error) {
-	s, ok := r.sources[name]
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	s, ok := r.sources[r.canonical(name)]
 	if !ok {
-		return nil, fmt.Errorf("unknown source connector %s", name)
+		return nil, fmt.Errorf("%w %s", ErrUnknownSource, name)
 	}
 	return s, nil
 }
 
-// DestinationByName fetches a registered destination.
+// DestinationByName fetches a registered destination by its name or an alias.
 func (r *Registry) DestinationByName(name string) (Destination, error) {
-	d, ok := r.destinations[name]
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	d, ok := r.destinations[r.canonical(name)]
 	if !ok {
-		return nil, fmt.Errorf("unknown destination connector %s", name)
+		return nil, fmt.Errorf("%w %s", ErrUnknownDestination, name)
 	}
 	return d, nil
 }
 
-// simulateValidation enforces the presence of fields without talking to external systems.
-func simulateValidation(required []string, config map[string]string) error {
-	for _, key := range required {
-		if config[key] == "" {
-			return fmt.Errorf("missing required config %s", key)
+// Lookup returns the metadata of the named source or destination, which may be given by an alias,
+// as it appears in Available.
+func (r *Registry) Lookup(name string, typ ConnectorType) (Connector, error) {
+	var canonical string
+	switch typ {
+	case SourceType:
+		src, err := r.SourceByName(name)
+		if err != nil {
+			return Connector{}, err
+		}
+		canonical = src.Info().Name
+	case DestinationType:
+		dst, err := r.DestinationByName(name)
+		if err != nil {
+			return Connector{}, err
+		}
+		canonical = dst.Info().Name
+	default:
+		return Connector{}, fmt.Errorf("unknown connector type %s", typ)
+	}
+	for _, c := range r.Available() {
+		if c.Type == typ && c.Name == canonical {
+			return c, nil
+		}
+	}
+	// only reachable if the connector was registered between the two lookups
+	return Connector{}, fmt.Errorf("connector %s changed while being looked up", name)
+}
+
+// HealthCheck probes every registered connector concurrently and returns its status keyed by
+// "type:name": "ok", or the error reported by the connector.
+func (r *Registry) HealthCheck(ctx context.Context) map[string]string {
+	checks := map[string]any{}
+	r.mu.RLock()
+	for name, src := range r.sources {
+		checks[string(SourceType)+":"+name] = src
+	}
+	for name, dst := range r.destinations {
+		checks[string(DestinationType)+":"+name] = dst
+	}
+	r.mu.RUnlock()
+
+	status := make(map[string]string, len(checks))
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for key, connector := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := "ok"
+			if hc, ok := connector.(HealthChecker); ok {
+				if err := hc.HealthCheck(ctx); err != nil {
+					result = err.Error()
+				}
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			status[key] = result
+		}()
+	}
+	wg.Wait()
+	return status
+}
+
+// simulateValidation enforces the presence and type of fields without talking to external systems.
+func simulateValidation(fields []ConfigField, config map[string]string) error {
+	for _, field := range fields {
+		value := config[field.Name]
+		if value == "" {
+			if field.Required {
+				return fmt.Errorf("missing required config %s", field.Name)
+			}
+			continue
+		}
+		switch field.Type {
+		case FieldInt:
+			if _, err := intConfig(config, field.Name, 0); err != nil {
+				return err
+			}
+		case FieldBool:
+			if _, err := strconv.ParseBool(value); err != nil {
+				return fmt.Errorf("config %s must be a boolean", field.Name)
+			}
 		}
 	}
 	return nil
 }
 
-// simulateTransfer mirrors network throughput with deterministic pacing.
-func simulateTransfer(ctx context.Context, records int) <-chan map[string]any {
-	out := make(chan map[string]any)
+// Validate checks config against the named source or destination without creating a pipeline.
+func (r *Registry) Validate(name string, typ ConnectorType, config map[string]string) error {
+	switch typ {
+	case SourceType:
+		src, err := r.SourceByName(name)
+		if err != nil {
+			return err
+		}
+		return src.Validate(config)
+	case DestinationType:
+		dst, err := r.DestinationByName(name)
+		if err != nil {
+			return err
+		}
+		return dst.Validate(config)
+	default:
+		return fmt.Errorf("unknown connector type %s", typ)
+	}
+}
+
+// Sample extracts up to limit (at least one) records from the named source and then stops the extraction.
+func (r *Registry) Sample(ctx context.Context, name string, config map[string]string, limit int) ([]map[string]any, error) {
+	src, err := r.SourceByName(name)
+	if err != nil {
+		return nil, err
+	}
+	// cancelling after the last wanted record lets the extract goroutine exit instead of blocking on send
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	records, err := src.Extract(WithExtractErrorHandler(ctx, ExtractErrorHandler(cancel)), config)
+	if err != nil {
+		return nil, err
+	}
+	sample := make([]map[string]any, 0, limit)
+	for record := range records {
+		sample = append(sample, record)
+		if len(sample) >= limit {
+			break
+		}
+	}
+	// the cause is a mid-stream extraction failure or the caller's cancellation
+	if err := context.Cause(ctx); err != nil {
+		return nil, err
+	}
+	return sample, nil
+}
+
+// intConfig parses an optional non-negative integer config value, falling back to def when unset.
+func intConfig(config map[string]string, key string, def int) (int, error) {
+	raw := config[key]
+	if raw == "" {
+		return def, nil
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("config %s must be an integer", key)
+	}
+	if v < 0 {
+		return 0, fmt.Errorf("config %s must not be negative", key)
+	}
+	return v, nil
+}
+
+// simulation shapes the records a simulated source produces.
+type simulation struct {
+	start  int
+	count  int
+	pacing time.Duration
+	buffer int
+}
+
+// simulationConfig reads recordCount (defaulting to def), startOffset, pacingMs (default 5), and
+// bufferSize from a validated source config.
+func simulationConfig(config map[string]string, def int) simulation {
+	count, _ := intConfig(config, "recordCount", def)
+	start, _ := intConfig(config, StartOffsetKey, 0)
+	pacing, _ := intConfig(config, "pacingMs", 5)
+	buffer, _ := intConfig(config, BufferSizeKey, 0)
+	return simulation{start: start, count: count, pacing: time.Duration(pacing) * time.Millisecond, buffer: buffer}
+}
+
+// simulateTransfer mirrors network throughput with deterministic pacing. Records carry an
+// incrementing offset beginning at sim.start so incremental runs can resume.
+func simulateTransfer(ctx context.Context, sim simulation) <-chan map[string]any {
+	return simulateRecords(ctx, sim, "record")
+}
+
+// simulateStream mirrors simulateTransfer for streaming sources.
+func simulateStream(ctx context.Context, sim simulation) <-chan map[string]any {
+	return simulateRecords(ctx, sim, "event")
+}
+
+// simulateRecords emits sim.count records, sleeping sim.pacing after each. Cancellation is checked
+// before every send, so zero pacing still stops promptly. On cancellation the channel is closed
+// with any buffered records still readable; consumers stop on ctx rather than draining them.
+func simulateRecords(ctx context.Context, sim simulation, kind string) <-chan map[string]any {
+	out := make(chan map[string]any, sim.buffer)
 	go func() {
 		defer close(out)
-		for i := 0; i < records; i++ {
+		for i := sim.start; i < sim.start+sim.count; i++ {
+			// select picks randomly between ready cases, so check first to stop promptly without pacing
+			if ctx.Err() != nil {
+				return
+			}
 			select {
 			case <-ctx.Done():
 				return
-			case out <- map[string]any{"id": i + 1, "payload": fmt.Sprintf("record-%d", i+1)}:
-				time.Sleep(5 * time.Millisecond)
+			case out <- map[string]any{"id": i + 1, "offset": int64(i), "payload": fmt.Sprintf("%s-%d", kind, i+1)}:
+				if sim.pacing > 0 {
+					time.Sleep(sim.pacing)
+				}
 			}
 		}
 	}()
 	return out
 }
 
+// RecordErrorHandler decides what happens when a destination fails to write one record. Returning
+// nil skips the record and lets the load continue; returning an error aborts the load with it.
+type RecordErrorHandler func(record map[string]any, err error) error
+
+type recordErrorKey struct{}
+
+// WithRecordErrorHandler returns a context whose loads hand per-record failures to h. Without a
+// handler the first failed record aborts the load.
+func WithRecordErrorHandler(ctx context.Context, h RecordErrorHandler) context.Context {
+	return context.WithValue(ctx, recordErrorKey{}, h)
+}
+
+// recordFailed applies the context's RecordErrorHandler to a record the destination could not write.
+func recordFailed(ctx context.Context, record map[string]any, err error) error {
+	if h, ok := ctx.Value(recordErrorKey{}).(RecordErrorHandler); ok {
+		return h(record, err)
+	}
+	return err
+}
+
+// ExtractErrorHandler receives a failure a source hits after Extract has returned, such as a
+// malformed element part way through a file. The source closes its channel right after, so the
+// handler is what tells a consumer the stream ended early rather than at its real end.
+type ExtractErrorHandler func(err error)
+
+type extractErrorKey struct{}
+
+// WithExtractErrorHandler returns a context whose extractions report mid-stream failures to h.
+func WithExtractErrorHandler(ctx context.Context, h ExtractErrorHandler) context.Context {
+	return context.WithValue(ctx, extractErrorKey{}, h)
+}
+
+// extractFailed hands err to the context's ExtractErrorHandler, or logs it when there is none.
+func extractFailed(ctx context.Context, err error) {
+	if h, ok := ctx.Value(extractErrorKey{}).(ExtractErrorHandler); ok {
+		h(err)
+		return
+	}
+	slog.ErrorContext(ctx, "extraction failed", "error", err)
+}
+
+// writeRecord mimics writing one record to dst, failing records with a field the destination
+// cannot store.
+func writeRecord(ctx context.Context, dst Connector, record map[string]any) error {
+	if err := checkRecord(dst, record); err != nil {
+		return recordFailed(ctx, record, err)
+	}
+	return nil
+}
+
 // consumeTransfer drains the channel to mimic load operations.
-func consumeTransfer(ctx context.Context, records <-chan map[string]any) error {
+func consumeTransfer(ctx context.Context, dst Connector, records <-chan map[string]any) error {
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
-		case _, ok := <-records:
+		case record, ok := <-records:
 			if !ok {
 				return nil
 			}
+			if err := writeRecord(ctx, dst, record); err != nil {
+				return err
+			}
 		}
 	}
 }
 
+// batchRecords groups records into slices of at most size, flushing any remainder when the input closes.
+func batchRecords(ctx context.Context, in <-chan map[string]any, size int) <-chan []map[string]any {
+	out := make(chan []map[string]any)
+	go func() {
+		defer close(out)
+		batch := make([]map[string]any, 0, size)
+		flush := func() bool {
+			if len(batch) == 0 {
+				return true
+			}
+			select {
+			case <-ctx.Done():
+				return false
+			case out <- batch:
+			}
+			batch = make([]map[string]any, 0, size)
+			return true
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case record, ok := <-in:
+				if !ok {
+					flush()
+					return
+				}
+				batch = append(batch, record)
+				if len(batch) == size && !flush() {
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
+
+// loadRecords drains records for a destination, grouping them into batches when batchSize is configured.
+func loadRecords(ctx context.Context, dst Connector, config map[string]string, records <-chan map[string]any) error {
+	size, _ := intConfig(config, "batchSize", 0)
+	if size == 0 {
+		return consumeTransfer(ctx, dst, records)
+	}
+	for batch := range batchRecords(ctx, records, size) {
+		written := 0
+		for _, record := range batch {
+			if err := writeRecord(ctx, dst, record); err != nil {
+				return err
+			}
+			written++
+		}
+		// flushing is simulated until destinations write to real systems
+		slog.InfoContext(ctx, "destination flushed batch", "destination", dst.Name, "records", written)
+	}
+	return ctx.Err()
+}
+
 // Basic connector implementations below operate in-memory while preserving validation paths.
 
 // MySQLSource extracts from MySQL.
@@ -157,6 +690,8 @@ func (s *MySQLSource) ensureMeta() {
 		Description: "High-speed MySQL binlog reader",
 		SupportsDDL: true,
 		MaxParallel: 8,
+		Mode:        ModeBatch,
+		Config:      withFields(sqlFields, sourceFields...),
 	}
 }
 
@@ -167,14 +702,14 @@ func (s *MySQLSource) Info() Connector {
 
 func (s *MySQLSource) Validate(config map[string]string) error {
 	s.ensureMeta()
-	return simulateValidation([]string{"host", "port", "user", "password", "database"}, config)
+	return simulateValidation(s.meta.Config, config)
 }
 
 func (s *MySQLSource) Extract(ctx context.Context, config map[string]string) (<-chan map[string]any, error) {
 	if err := s.Validate(config); err != nil {
 		return nil, err
 	}
-	return simulateTransfer(ctx, 50), nil
+	return simulateTransfer(ctx, simulationConfig(config, 50)), nil
 }
 
 // PostgresSource extracts from Postgres logical replication.
@@ -190,6 +725,8 @@ func (s *PostgresSource) ensureMeta() {
 		Description: "Logical replication with parallel snapshot",
 		SupportsDDL: true,
 		MaxParallel: 8,
+		Mode:        ModeBatch,
+		Config:      withFields(sqlFields, sourceFields...),
 	}
 }
 
@@ -200,14 +737,14 @@ func (s *PostgresSource) Info() Connector {
 
 func (s *PostgresSource) Validate(config map[string]string) error {
 	s.ensureMeta()
-	return simulateValidation([]string{"host", "port", "user", "password", "database"}, config)
+	return simulateValidation(s.meta.Config, config)
 }
 
 func (s *PostgresSource) Extract(ctx context.Context, config map[string]string) (<-chan map[string]any, error) {
 	if err := s.Validate(config); err != nil {
 		return nil, err
 	}
-	return simulateTransfer(ctx, 50), nil
+	return simulateTransfer(ctx, simulationConfig(config, 50)), nil
 }
 
 // SQLServerSource extracts from SQL Server CDC.
@@ -223,6 +760,8 @@ func (s *SQLServerSource) ensureMeta() {
 		Description: "SQL Server CDC with snapshot fallback",
 		SupportsDDL: true,
 		MaxParallel: 4,
+		Mode:        ModeBatch,
+		Config:      withFields(sqlFields, sourceFields...),
 	}
 }
 
@@ -233,14 +772,14 @@ func (s *SQLServerSource) Info() Connector {
 
 func (s *SQLServerSource) Validate(config map[string]string) error {
 	s.ensureMeta()
-	return simulateValidation([]string{"host", "port", "user", "password", "database"}, config)
+	return simulateValidation(s.meta.Config, config)
 }
 
 func (s *SQLServerSource) Extract(ctx context.Context, config map[string]string) (<-chan map[string]any, error) {
 	if err := s.Validate(config); err != nil {
 		return nil, err
 	}
-	return simulateTransfer(ctx, 50), nil
+	return simulateTransfer(ctx, simulationConfig(config, 50)), nil
 }
 
 // IcebergSource extracts from Apache Iceberg tables.
@@ -256,6 +795,8 @@ func (s *IcebergSource) ensureMeta() {
 		Description: "Snapshot reads over Apache Iceberg metadata",
 		SupportsDDL: false,
 		MaxParallel: 6,
+		Mode:        ModeBatch,
+		Config:      withFields(icebergFields, sourceFields...),
 	}
 }
 
@@ -266,14 +807,97 @@ func (s *IcebergSource) Info() Connector {
 
 func (s *IcebergSource) Validate(config map[string]string) error {
 	s.ensureMeta()
-	return simulateValidation([]string{"catalog", "table", "warehouse"}, config)
+	return simulateValidation(s.meta.Config, config)
 }
 
 func (s *IcebergSource) Extract(ctx context.Context, config map[string]string) (<-chan map[string]any, error) {
 	if err := s.Validate(config); err != nil {
 		return nil, err
 	}
-	return simulateTransfer(ctx, 30), nil
+	return simulateTransfer(ctx, simulationConfig(config, 30)), nil
+}
+
+// S3Source extracts newline-delimited JSON objects staged in S3.
+type S3Source struct{ meta Connector }
+
+func (s *S3Source) ensureMeta() {
+	if s.meta.Name != "" {
+		return
+	}
+	s.meta = Connector{
+		Name:        "s3",
+		Type:        SourceType,
+		Description: "Newline-delimited JSON objects under an S3 prefix",
+		SupportsDDL: false,
+		MaxParallel: 16,
+		Mode:        ModeBatch,
+		Config: withFields([]ConfigField{
+			{Name: "bucket", Type: FieldString, Required: true},
+			{Name: "prefix", Type: FieldString, Required: true},
+			{Name: "region", Type: FieldString, Required: true},
+		}, sourceFields...),
+	}
+}
+
+func (s *S3Source) Info() Connector {
+	s.ensureMeta()
+	return s.meta
+}
+
+func (s *S3Source) Validate(config map[string]string) error {
+	s.ensureMeta()
+	return simulateValidation(s.meta.Config, config)
+}
+
+func (s *S3Source) Extract(ctx context.Context, config map[string]string) (<-chan map[string]any, error) {
+	if err := s.Validate(config); err != nil {
+		return nil, err
+	}
+	// each simulated record stands in for one decoded NDJSON line
+	return simulateTransfer(ctx, simulationConfig(config, 40)), nil
+}
+
+// KafkaSource consumes change events from a Kafka topic.
+type KafkaSource struct {
+	meta Connector
+	// Partitions is the topic partition count, which bounds consumer parallelism.
+	Partitions int
+}
+
+func (s *KafkaSource) ensureMeta() {
+	if s.meta.Name != "" {
+		return
+	}
+	s.meta = Connector{
+		Name:        "kafka",
+		Type:        SourceType,
+		Description: "Consumer group reads of change events from a topic",
+		SupportsDDL: false,
+		MaxParallel: max(s.Partitions, 1),
+		Mode:        ModeStreaming,
+		Config: withFields([]ConfigField{
+			{Name: "brokers", Type: FieldString, Required: true},
+			{Name: "topic", Type: FieldString, Required: true},
+			{Name: "groupId", Type: FieldString, Required: true},
+		}, sourceFields...),
+	}
+}
+
+func (s *KafkaSource) Info() Connector {
+	s.ensureMeta()
+	return s.meta
+}
+
+func (s *KafkaSource) Validate(config map[string]string) error {
+	s.ensureMeta()
+	return simulateValidation(s.meta.Config, config)
+}
+
+func (s *KafkaSource) Extract(ctx context.Context, config map[string]string) (<-chan map[string]any, error) {
+	if err := s.Validate(config); err != nil {
+		return nil, err
+	}
+	return simulateStream(ctx, simulationConfig(config, 50)), nil
 }
 
 // MySQLDestination loads into MySQL.
@@ -284,11 +908,14 @@ func (d *MySQLDestination) ensureMeta() {
 		return
 	}
 	d.meta = Connector{
-		Name:        "mysql",
-		Type:        DestinationType,
-		Description: "Batch inserts with parallel writers",
-		SupportsDDL: true,
-		MaxParallel: 8,
+		Name:           "mysql",
+		Type:           DestinationType,
+		Description:    "Batch inserts with parallel writers",
+		SupportsDDL:    true,
+		MaxParallel:    8,
+		Mode:           ModeStreaming,
+		Config:         withFields(sqlFields, batchSizeField),
+		SupportedTypes: columnTypes,
 	}
 }
 
@@ -299,14 +926,14 @@ func (d *MySQLDestination) Info() Connector {
 
 func (d *MySQLDestination) Validate(config map[string]string) error {
 	d.ensureMeta()
-	return simulateValidation([]string{"host", "port", "user", "password", "database"}, config)
+	return simulateValidation(d.meta.Config, config)
 }
 
 func (d *MySQLDestination) Load(ctx context.Context, config map[string]string, records <-chan map[string]any) error {
 	if err := d.Validate(config); err != nil {
 		return err
 	}
-	return consumeTransfer(ctx, records)
+	return loadRecords(ctx, d.meta, config, records)
 }
 
 // PostgresDestination loads into Postgres.
@@ -317,11 +944,14 @@ func (d *PostgresDestination) ensureMeta() {
 		return
 	}
 	d.meta = Connector{
-		Name:        "postgres",
-		Type:        DestinationType,
-		Description: "COPY protocol with conflict handling",
-		SupportsDDL: true,
-		MaxParallel: 8,
+		Name:           "postgres",
+		Type:           DestinationType,
+		Description:    "COPY protocol with conflict handling",
+		SupportsDDL:    true,
+		MaxParallel:    8,
+		Mode:           ModeStreaming,
+		Config:         withFields(sqlFields, batchSizeField),
+		SupportedTypes: columnTypes,
 	}
 }
 
@@ -332,14 +962,14 @@ func (d *PostgresDestination) Info() Connector {
 
 func (d *PostgresDestination) Validate(config map[string]string) error {
 	d.ensureMeta()
-	return simulateValidation([]string{"host", "port", "user", "password", "database"}, config)
+	return simulateValidation(d.meta.Config, config)
 }
 
 func (d *PostgresDestination) Load(ctx context.Context, config map[string]string, records <-chan map[string]any) error {
 	if err := d.Validate(config); err != nil {
 		return err
 	}
-	return consumeTransfer(ctx, records)
+	return loadRecords(ctx, d.meta, config, records)
 }
 
 // SQLServerDestination loads into SQL Server.
@@ -350,11 +980,14 @@ func (d *SQLServerDestination) ensureMeta() {
 		return
 	}
 	d.meta = Connector{
-		Name:        "sqlserver",
-		Type:        DestinationType,
-		Description: "Bulk copy optimized for columnstore",
-		SupportsDDL: true,
-		MaxParallel: 4,
+		Name:           "sqlserver",
+		Type:           DestinationType,
+		Description:    "Bulk copy optimized for columnstore",
+		SupportsDDL:    true,
+		MaxParallel:    4,
+		Mode:           ModeStreaming,
+		Config:         withFields(sqlFields, batchSizeField),
+		SupportedTypes: columnTypes,
 	}
 }
 
@@ -365,23 +998,245 @@ func (d *SQLServerDestination) Info() Connector {
 
 func (d *SQLServerDestination) Validate(config map[string]string) error {
 	d.ensureMeta()
-	return simulateValidation([]string{"host", "port", "user", "password", "database"}, config)
+	return simulateValidation(d.meta.Config, config)
 }
 
 func (d *SQLServerDestination) Load(ctx context.Context, config map[string]string, records <-chan map[string]any) error {
 	if err := d.Validate(config); err != nil {
 		return err
 	}
-	return consumeTransfer(ctx, records)
+	return loadRecords(ctx, d.meta, config, records)
 }
 
-// ValidateConnectorPair ensures source and destination are compatible.
-func ValidateConnectorPair(src Connector, dst Connector) error {
-	if src.Type != SourceType || dst.Type != DestinationType {
-		return errors.New("invalid connector pairing")
+// MongoDestination loads into MongoDB collections.
+type MongoDestination struct{ meta Connector }
+
+func (d *MongoDestination) ensureMeta() {
+	if d.meta.Name != "" {
+		return
+	}
+	d.meta = Connector{
+		Name:        "mongodb",
+		Type:        DestinationType,
+		Description: "Unordered bulk writes into a collection",
+		SupportsDDL: false,
+		MaxParallel: 4,
+		Mode:        ModeStreaming,
+		Config: []ConfigField{
+			{Name: "uri", Type: FieldString, Secret: true, Required: true},
+			{Name: "database", Type: FieldString, Required: true},
+			{Name: "collection", Type: FieldString, Required: true},
+			batchSizeField,
+		},
+	}
+}
+
+func (d *MongoDestination) Info() Connector {
+	d.ensureMeta()
+	return d.meta
+}
+
+func (d *MongoDestination) Validate(config map[string]string) error {
+	d.ensureMeta()
+	return simulateValidation(d.meta.Config, config)
+}
+
+func (d *MongoDestination) Load(ctx context.Context, config map[string]string, records <-chan map[string]any) error {
+	if err := d.Validate(config); err != nil {
+		return err
+	}
+	return loadRecords(ctx, d.meta, config, records)
+}
+
+// IcebergDestination writes into Apache Iceberg tables.
+type IcebergDestination struct{ meta Connector }
+
+func (d *IcebergDestination) ensureMeta() {
+	if d.meta.Name != "" {
+		return
 	}
-	if src.Name == dst.Name && src.Name == "iceberg" {
-		return errors.New("iceberg cannot be a destination")
+	d.meta = Connector{
+		Name:        "iceberg",
+		Type:        DestinationType,
+		Description: "Append commits of Parquet data files to Iceberg tables",
+		SupportsDDL: false,
+		MaxParallel: 6,
+		Mode:        ModeBatch,
+		Config:      withFields(icebergFields, batchSizeField),
+	}
+}
+
+func (d *IcebergDestination) Info() Connector {
+	d.ensureMeta()
+	return d.meta
+}
+
+func (d *IcebergDestination) Validate(config map[string]string) error {
+	d.ensureMeta()
+	return simulateValidation(d.meta.Config, config)
+}
+
+func (d *IcebergDestination) Load(ctx context.Context, config map[string]string, records <-chan map[string]any) error {
+	if err := d.Validate(config); err != nil {
+		return err
+	}
+	return loadRecords(ctx, d.meta, config, records)
+}
+
+// SnowflakeDestination loads into Snowflake tables.
+type SnowflakeDestination struct{ meta Connector }
+
+func (d *SnowflakeDestination) ensureMeta() {
+	if d.meta.Name != "" {
+		return
+	}
+	d.meta = Connector{
+		Name:        "snowflake",
+		Type:        DestinationType,
+		Description: "Staged batch loads into a warehouse table",
+		SupportsDDL: true,
+		MaxParallel: 4,
+		Mode:        ModeStreaming,
+		Config:      withFields(snowflakeFields, batchSizeField),
+		// nested values land in VARIANT columns
+		SupportedTypes: []string{TypeInt, TypeFloat, TypeString, TypeBool, TypeObject, TypeArray},
+	}
+}
+
+func (d *SnowflakeDestination) Info() Connector {
+	d.ensureMeta()
+	return d.meta
+}
+
+func (d *SnowflakeDestination) Validate(config map[string]string) error {
+	d.ensureMeta()
+	return simulateValidation(d.meta.Config, config)
+}
+
+func (d *SnowflakeDestination) Load(ctx context.Context, config map[string]string, records <-chan map[string]any) error {
+	if err := d.Validate(config); err != nil {
+		return err
+	}
+	return loadRecords(ctx, d.meta, config, records)
+}
+
+// NullDestination discards every record, giving a baseline for extraction throughput.
+type NullDestination struct{ meta Connector }
+
+func (d *NullDestination) ensureMeta() {
+	if d.meta.Name != "" {
+		return
+	}
+	d.meta = Connector{
+		Name:        "null",
+		Type:        DestinationType,
+		Description: "Discards records as fast as they arrive",
+		SupportsDDL: false,
+		MaxParallel: 64,
+		Mode:        ModeStreaming,
+		Config:      []ConfigField{},
+	}
+}
+
+func (d *NullDestination) Info() Connector {
+	d.ensureMeta()
+	return d.meta
+}
+
+func (d *NullDestination) Validate(map[string]string) error {
+	return nil
+}
+
+// Load drains records without a select per record. The stages feeding it close the channel on
+// cancellation, so ctx is only consulted once the channel is closed.
+func (d *NullDestination) Load(ctx context.Context, _ map[string]string, records <-chan map[string]any) error {
+	for range records {
+	}
+	return ctx.Err()
+}
+
+// ValidateConnectorPair ensures source and destination are compatible. Pairing a streaming source
+// with a batch destination is rejected when strict is set and otherwise only logged as a warning.
+func ValidateConnectorPair(src Connector, dst Connector, strict bool) error {
+	warning, err := checkPair(src, dst)
+	if err != nil {
+		return err
+	}
+	if warning != nil {
+		if strict {
+			return warning
+		}
+		slog.Warn("discouraged connector pairing", "source", src.Name, "destination", dst.Name, "warning", warning)
 	}
 	return nil
 }
+
+// checkPair applies the pairing rules, separating pairings that are discouraged (warning) from
+// those that cannot work (err).
+func checkPair(src Connector, dst Connector) (warning, err error) {
+	if src.Type != SourceType || dst.Type != DestinationType {
+		if dst.Name == "iceberg" && dst.Type == SourceType {
+			return nil, errors.New("the iceberg source cannot be used as a destination")
+		}
+		return nil, errors.New("invalid connector pairing")
+	}
+	if src.Mode == ModeStreaming && dst.Mode == ModeBatch {
+		return fmt.Errorf("streaming source %s should not feed batch destination %s", src.Name, dst.Name), nil
+	}
+	return nil, nil
+}
+
+// Compatibility is one source/destination cell of the compatibility matrix. Warning is set for a
+// pairing that is allowed but discouraged.
+type Compatibility struct {
+	Compatible bool   `json:"compatible"`
+	Reason     string `json:"reason,omitempty"`
+	Warning    string `json:"warning,omitempty"`
+}
+
+// Compatibility applies the ValidateConnectorPair rules to every registered source and destination,
+// keyed by source name and then destination name. Config-dependent rules such as
+// ValidateNotSelfLoop are not reflected.
+func (r *Registry) Compatibility(strict bool) map[string]map[string]Compatibility {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	matrix := make(map[string]map[string]Compatibility, len(r.sources))
+	for srcName, src := range r.sources {
+		row := make(map[string]Compatibility, len(r.destinations))
+		for dstName, dst := range r.destinations {
+			warning, err := checkPair(src.Info(), dst.Info())
+			if warning != nil && strict {
+				err = warning
+			}
+			switch {
+			case err != nil:
+				row[dstName] = Compatibility{Reason: err.Error()}
+			case warning != nil:
+				row[dstName] = Compatibility{Compatible: true, Warning: warning.Error()}
+			default:
+				row[dstName] = Compatibility{Compatible: true}
+			}
+		}
+		matrix[srcName] = row
+	}
+	return matrix
+}
+
+// locationKeys lists the config keys that identify the physical dataset a connector reads or writes.
+var locationKeys = map[string][]string{
+	"iceberg": {"catalog", "warehouse", "table"},
+}
+
+// ValidateNotSelfLoop rejects pipelines whose source and destination point at the same dataset.
+func ValidateNotSelfLoop(src Connector, dst Connector, srcConfig, dstConfig map[string]string) error {
+	keys, ok := locationKeys[src.Name]
+	if !ok || src.Name != dst.Name {
+		return nil
+	}
+	for _, key := range keys {
+		if srcConfig[key] != dstConfig[key] {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s source and destination refer to the same table", src.Name)
+}
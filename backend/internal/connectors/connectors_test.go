@@ -0,0 +1,46 @@
+package connectors
+
+import (
+	"errors"
+	"testing"
+)
+
+// renamedSource is the http source registered under another name.
+type renamedSource struct {
+	HTTPSource
+	name string
+}
+
+func (s *renamedSource) Info() Connector {
+	info := s.HTTPSource.Info()
+	info.Name = s.name
+	return info
+}
+
+func TestRegisterDuplicate(t *testing.T) {
+	reg, err := NewRegistry()
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+
+	if err := reg.RegisterSource(&renamedSource{name: "custom"}); err != nil {
+		t.Fatalf("first RegisterSource: %v", err)
+	}
+	if err := reg.RegisterSource(&renamedSource{name: "custom"}); !errors.Is(err, ErrConnectorExists) {
+		t.Fatalf("second RegisterSource = %v, want ErrConnectorExists", err)
+	}
+	if err := reg.RegisterSource(&HTTPSource{}); !errors.Is(err, ErrConnectorExists) {
+		t.Fatalf("RegisterSource of a built-in = %v, want ErrConnectorExists", err)
+	}
+	if err := reg.RegisterDestination(&NullDestination{}); !errors.Is(err, ErrConnectorExists) {
+		t.Fatalf("RegisterDestination of a built-in = %v, want ErrConnectorExists", err)
+	}
+
+	// a source and a destination may share a name
+	if _, err := reg.SourceByName("mysql"); err != nil {
+		t.Fatalf("SourceByName: %v", err)
+	}
+	if _, err := reg.DestinationByName("mysql"); err != nil {
+		t.Fatalf("DestinationByName: %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+package connectors
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// JSONFileRootEnv names the environment variable holding the directory the jsonfile source reads
+// from. NewRegistry reads it once.
+const JSONFileRootEnv = "JSONFILE_ROOT"
+
+// JSONFileSource reads records from a local file holding a JSON array of objects, which makes
+// runs reproducible without the simulated sources. Paths resolve under Root, and one that leaves it,
+// through "..", an absolute path elsewhere or a symlink, is refused; with no Root every path is.
+type JSONFileSource struct {
+	Root string
+	meta Connector
+}
+
+func (s *JSONFileSource) ensureMeta() {
+	if s.meta.Name != "" {
+		return
+	}
+	s.meta = Connector{
+		Name:        "jsonfile",
+		Type:        SourceType,
+		Description: "Objects streamed from a local JSON array file",
+		SupportsDDL: false,
+		MaxParallel: 4,
+		Mode:        ModeBatch,
+		Config: []ConfigField{
+			{Name: "path", Type: FieldString, Required: true},
+			bufferField,
+		},
+	}
+}
+
+func (s *JSONFileSource) Info() Connector {
+	s.ensureMeta()
+	return s.meta
+}
+
+func (s *JSONFileSource) Validate(config map[string]string) error {
+	s.ensureMeta()
+	if err := simulateValidation(s.meta.Config, config); err != nil {
+		return err
+	}
+	f, err := s.open(config["path"])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("config path: %w", err)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("config path %s is a directory", config["path"])
+	}
+	return nil
+}
+
+// open opens path within Root. A relative path is taken from Root and an absolute one must lie
+// inside it; os.Root then refuses any step, symlinks included, that would leave the directory.
+func (s *JSONFileSource) open(path string) (*os.File, error) {
+	if s.Root == "" {
+		return nil, fmt.Errorf("config path: the jsonfile source is disabled until %s names the directory it reads from", JSONFileRootEnv)
+	}
+	name := path
+	if filepath.IsAbs(path) {
+		base, err := filepath.Abs(s.Root)
+		if err != nil {
+			return nil, fmt.Errorf("config path: %w", err)
+		}
+		rel, err := filepath.Rel(base, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("config path %s is outside %s", path, s.Root)
+		}
+		name = rel
+	}
+	root, err := os.OpenRoot(s.Root)
+	if err != nil {
+		return nil, fmt.Errorf("config path: %w", err)
+	}
+	defer root.Close()
+	f, err := root.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("config path: %w", err)
+	}
+	return f, nil
+}
+
+// Extract decodes the array one element at a time, so the file is never held in memory. A file
+// that does not start with an array fails immediately; an element that is not an object, or is
+// cut off, ends the extraction and is reported through the context's ExtractErrorHandler.
+func (s *JSONFileSource) Extract(ctx context.Context, config map[string]string) (<-chan map[string]any, error) {
+	if err := s.Validate(config); err != nil {
+		return nil, err
+	}
+	path := config["path"]
+	f, err := s.open(path)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(f)
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+		f.Close()
+		return nil, fmt.Errorf("%s does not contain a JSON array", path)
+	}
+	buffer, _ := intConfig(config, BufferSizeKey, 0)
+	out := make(chan map[string]any, buffer)
+	go func() {
+		defer close(out)
+		defer f.Close()
+		for i := 0; dec.More(); i++ {
+			var record map[string]any
+			if err := dec.Decode(&record); err != nil {
+				var typeErr *json.UnmarshalTypeError
+				if errors.As(err, &typeErr) {
+					err = fmt.Errorf("element %d is not an object", i)
+				} else if errors.Is(err, io.ErrUnexpectedEOF) {
+					err = fmt.Errorf("element %d is truncated", i)
+				}
+				extractFailed(ctx, fmt.Errorf("read %s: %w", path, err))
+				return
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case out <- record:
+			}
+		}
+	}()
+	return out, nil
+}
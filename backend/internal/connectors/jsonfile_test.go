@@ -0,0 +1,98 @@
+package connectors
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeJSONFile writes data to a file in a test directory, makes that directory the jsonfile root
+// and returns the file's path.
+func writeJSONFile(t *testing.T, data string) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv(JSONFileRootEnv, dir)
+	path := filepath.Join(dir, "records.json")
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// sampleJSONFile samples up to 10 records of the file at path through a fresh registry.
+func sampleJSONFile(t *testing.T, path string) ([]map[string]any, error) {
+	t.Helper()
+	registry, err := NewRegistry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return registry.Sample(context.Background(), "jsonfile", map[string]string{"path": path}, 10)
+}
+
+func TestJSONFileSample(t *testing.T) {
+	path := writeJSONFile(t, `[{"id":1},{"id":2},{"id":3}]`)
+	sample, err := sampleJSONFile(t, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sample) != 3 {
+		t.Fatalf("got %d records, want 3", len(sample))
+	}
+}
+
+func TestJSONFileMalformedElement(t *testing.T) {
+	for _, tc := range []struct{ name, data, want string }{
+		{"truncated", `[{"id":1},{"id":2},{"id":`, "element 2 is truncated"},
+		{"not an object", `[{"id":1},2]`, "element 1 is not an object"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			path := writeJSONFile(t, tc.data)
+			_, err := sampleJSONFile(t, path)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("got error %v, want one containing %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestJSONFileRoot(t *testing.T) {
+	path := writeJSONFile(t, `[{"id":1}]`)
+	root := filepath.Dir(path)
+	outside := filepath.Join(t.TempDir(), "secret.json")
+	if err := os.WriteFile(outside, []byte(`[{"secret":true}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "link.json")); err != nil {
+		t.Fatal(err)
+	}
+	escape, err := filepath.Rel(root, outside)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name, path string
+		ok         bool
+	}{
+		{"relative", "records.json", true},
+		{"absolute inside", path, true},
+		{"parent escape", escape, false},
+		{"absolute outside", outside, false},
+		{"symlink out", "link.json", false},
+		{"root itself", root, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := sampleJSONFile(t, tc.path)
+			if tc.ok != (err == nil) {
+				t.Fatalf("Sample(%s) = %v, want ok %v", tc.path, err, tc.ok)
+			}
+		})
+	}
+
+	t.Setenv(JSONFileRootEnv, "")
+	if _, err := sampleJSONFile(t, path); err == nil || !strings.Contains(err.Error(), JSONFileRootEnv) {
+		t.Fatalf("Sample with no root = %v, want it refused", err)
+	}
+}
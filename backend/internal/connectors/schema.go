@@ -0,0 +1,131 @@
+package connectors
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Inferred field types.
+const (
+	TypeInt    = "int"
+	TypeFloat  = "float"
+	TypeString = "string"
+	TypeBool   = "bool"
+	TypeObject = "object"
+	TypeArray  = "array"
+	TypeNull   = "null"
+)
+
+// FieldSchema describes one field inferred from sampled records.
+type FieldSchema struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Nullable bool   `json:"nullable"`
+	Note     string `json:"note,omitempty"`
+}
+
+// InferSchema derives field types from a sample. Fields that are missing or null in some records are
+// nullable; ints mixed with floats widen to float, and any other conflict widens to string with a note.
+func InferSchema(records []map[string]any) []FieldSchema {
+	fields := map[string]*FieldSchema{}
+	seen := map[string]int{}
+	for _, record := range records {
+		for name, value := range record {
+			seen[name]++
+			typ := valueType(value)
+			f, ok := fields[name]
+			if !ok {
+				f = &FieldSchema{Name: name, Type: typ}
+				fields[name] = f
+			}
+			if typ == TypeNull {
+				f.Nullable = true
+				continue
+			}
+			f.Type, f.Note = widen(f.Type, typ, f.Note)
+		}
+	}
+
+	result := make([]FieldSchema, 0, len(fields))
+	for name, f := range fields {
+		if seen[name] < len(records) {
+			f.Nullable = true
+		}
+		if f.Type == TypeNull {
+			// only nulls were sampled, so nothing narrower than string is safe
+			f.Type = TypeString
+		}
+		result = append(result, *f)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// widen combines the type seen so far with a newly observed one.
+func widen(current, observed, note string) (string, string) {
+	switch {
+	case current == observed || current == TypeString && note != "":
+		return current, note
+	case current == TypeNull:
+		return observed, note
+	case current == TypeInt && observed == TypeFloat, current == TypeFloat && observed == TypeInt:
+		return TypeFloat, note
+	default:
+		return TypeString, fmt.Sprintf("conflicting types %s and %s widened to string", current, observed)
+	}
+}
+
+// valueType maps a decoded Go value onto an inferred field type.
+func valueType(v any) string {
+	switch v.(type) {
+	case nil:
+		return TypeNull
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return TypeInt
+	case float32, float64:
+		return TypeFloat
+	case bool:
+		return TypeBool
+	case string:
+		return TypeString
+	case map[string]any:
+		return TypeObject
+	case []any:
+		return TypeArray
+	default:
+		return TypeString
+	}
+}
+
+// checkRecord verifies that dst can store every non-null field of one record.
+func checkRecord(dst Connector, record map[string]any) error {
+	if len(dst.SupportedTypes) == 0 {
+		return nil
+	}
+	for name, value := range record {
+		if typ := valueType(value); typ != TypeNull && !slices.Contains(dst.SupportedTypes, typ) {
+			return fmt.Errorf("destination %s cannot store field %s (%s)", dst.Name, name, typ)
+		}
+	}
+	return nil
+}
+
+// CheckSchema verifies that dst can store every field in fields, listing the fields it cannot.
+func CheckSchema(dst Connector, fields []FieldSchema) error {
+	if len(dst.SupportedTypes) == 0 {
+		return nil
+	}
+	var incompatible []string
+	for _, f := range fields {
+		if !slices.Contains(dst.SupportedTypes, f.Type) {
+			incompatible = append(incompatible, fmt.Sprintf("%s (%s)", f.Name, f.Type))
+		}
+	}
+	if len(incompatible) > 0 {
+		return fmt.Errorf("destination %s cannot store fields %s; it supports %s",
+			dst.Name, strings.Join(incompatible, ", "), strings.Join(dst.SupportedTypes, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package connectors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+)
+
+// StdoutDestination writes each record as a line of JSON, for local debugging.
+type StdoutDestination struct {
+	meta Connector
+	// Out receives the records; NewRegistry sets it to os.Stdout.
+	Out io.Writer
+	// mu keeps records from concurrent loads on separate lines
+	mu sync.Mutex
+}
+
+func (d *StdoutDestination) ensureMeta() {
+	if d.meta.Name != "" {
+		return
+	}
+	d.meta = Connector{
+		Name:        "stdout",
+		Type:        DestinationType,
+		Description: "Prints each record as JSON for debugging",
+		SupportsDDL: false,
+		MaxParallel: 1,
+		Mode:        ModeStreaming,
+		Config: []ConfigField{
+			{Name: "pretty", Type: FieldBool},
+		},
+	}
+}
+
+func (d *StdoutDestination) Info() Connector {
+	d.ensureMeta()
+	return d.meta
+}
+
+func (d *StdoutDestination) Validate(config map[string]string) error {
+	d.ensureMeta()
+	return simulateValidation(d.meta.Config, config)
+}
+
+// Load prints records until the channel closes, setting pretty indents each record over several lines.
+func (d *StdoutDestination) Load(ctx context.Context, config map[string]string, records <-chan map[string]any) error {
+	if err := d.Validate(config); err != nil {
+		return err
+	}
+	pretty, _ := strconv.ParseBool(config["pretty"])
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case record, ok := <-records:
+			if !ok {
+				return nil
+			}
+			line, err := encodeRecord(record, pretty)
+			if err != nil {
+				// a record that cannot be encoded fails on its own, like a row a database rejects
+				if err := recordFailed(ctx, record, err); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := d.write(line); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func encodeRecord(record map[string]any, pretty bool) ([]byte, error) {
+	var (
+		line []byte
+		err  error
+	)
+	if pretty {
+		line, err = json.MarshalIndent(record, "", "  ")
+	} else {
+		line, err = json.Marshal(record)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("encode record: %w", err)
+	}
+	return append(line, '\n'), nil
+}
+
+func (d *StdoutDestination) write(line []byte) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	_, err := d.Out.Write(line)
+	return err
+}
@@ -0,0 +1,38 @@
+package pipeline
+
+import "errors"
+
+// ErrServerBusy is the error of runs turned away because the concurrent run ceiling is reached.
+var ErrServerBusy = errors.New("server busy: too many runs in flight, retry later")
+
+// SetMaxConcurrentRuns caps the runs in flight across all pipelines, counting both synchronous runs
+// and asynchronous ones from when they are queued until they finish. Runs beyond the cap are
+// rejected with ErrServerBusy rather than slowing every run down. 0, the default, means no cap.
+func (s *Service) SetMaxConcurrentRuns(n int) {
+	s.maxRuns.Store(int64(max(n, 0)))
+}
+
+// Busy reports whether a new run would be rejected with ErrServerBusy.
+func (s *Service) Busy() bool {
+	limit := s.maxRuns.Load()
+	return limit > 0 && s.admitted.Load() >= limit
+}
+
+// admitRun takes one of the run slots, reporting false when none is left. Every admitted run must
+// be matched by exactly one releaseRun.
+func (s *Service) admitRun() bool {
+	for {
+		n := s.admitted.Load()
+		if limit := s.maxRuns.Load(); limit > 0 && n >= limit {
+			return false
+		}
+		if s.admitted.CompareAndSwap(n, n+1) {
+			return true
+		}
+	}
+}
+
+// releaseRun gives back a slot taken by admitRun.
+func (s *Service) releaseRun() {
+	s.admitted.Add(-1)
+}
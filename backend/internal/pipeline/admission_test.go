@@ -0,0 +1,36 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestResultErr(t *testing.T) {
+	svc := newFailingService(t)
+	svc.SetMaxConcurrentRuns(1)
+	cfg := testConfig("failing", 20)
+	cfg.DestType = "failing"
+	cfg.MaxRetries = 1
+	if err := svc.Create(cfg); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if res := svc.Run(context.Background(), "missing"); !errors.Is(res.Err, ErrPipelineNotFound) {
+		t.Fatalf("Run of a missing pipeline: Err = %v", res.Err)
+	}
+	res := svc.Run(context.Background(), "failing")
+	if res.Err == nil || res.Err.Error() != res.Error || res.Error != "destination rejected the batch (after 2 attempts)" {
+		t.Fatalf("Run = %q with Err %v", res.Error, res.Err)
+	}
+
+	// hold the only run slot so the next run is shed
+	if !svc.admitRun() {
+		t.Fatal("no run slot free")
+	}
+	defer svc.releaseRun()
+	res = svc.Run(context.Background(), "failing")
+	if res.Status != StatusFailed || !errors.Is(res.Err, ErrServerBusy) {
+		t.Fatalf("shed Run = %s with Err %v, want ErrServerBusy", res.Status, res.Err)
+	}
+}
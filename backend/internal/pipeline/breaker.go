@@ -0,0 +1,160 @@
+package pipeline
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Circuit breaker defaults, used unless SetCircuitBreaker changes them.
+const (
+	DefaultBreakerThreshold = 5
+	DefaultBreakerCooldown  = time.Minute
+)
+
+// Circuit states reported in BreakerStatus.State.
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half-open"
+)
+
+// ErrCircuitOpen is returned for runs short-circuited because a destination's breaker is open.
+var ErrCircuitOpen = errors.New("circuit open")
+
+// circuitBreakers counts consecutive load failures per destination. Once a destination reaches the
+// threshold its circuit opens and runs loading into it fail without starting until the cooldown
+// has passed. The circuit is then half-open and lets a single trial run through, turning away the
+// rest while it is in flight: a success closes the circuit and a failure opens it again for another
+// cooldown.
+type circuitBreakers struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	states    map[string]*breakerState
+}
+
+type breakerState struct {
+	failures int
+	openedAt time.Time
+	// probing is set while the trial run of a half-open circuit is in flight
+	probing bool
+}
+
+// BreakerStatus describes the circuit of one destination.
+type BreakerStatus struct {
+	Destination string     `json:"destination"`
+	State       string     `json:"state"`
+	Failures    int        `json:"failures"`
+	OpenUntil   *time.Time `json:"openUntil,omitempty"`
+}
+
+func newCircuitBreakers() *circuitBreakers {
+	return &circuitBreakers{threshold: DefaultBreakerThreshold, cooldown: DefaultBreakerCooldown, states: map[string]*breakerState{}}
+}
+
+// SetCircuitBreaker sets how many consecutive load failures open a destination's circuit and how
+// long it stays open. A threshold of 0 disables the breakers.
+func (s *Service) SetCircuitBreaker(threshold int, cooldown time.Duration) {
+	s.breakers.mu.Lock()
+	defer s.breakers.mu.Unlock()
+	s.breakers.threshold = max(threshold, 0)
+	s.breakers.cooldown = max(cooldown, 0)
+}
+
+// Breakers returns the circuit of every destination that has recorded a load, sorted by name.
+func (s *Service) Breakers() []BreakerStatus {
+	b := s.breakers
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	result := make([]BreakerStatus, 0, len(b.states))
+	for name, st := range b.states {
+		status := BreakerStatus{Destination: name, State: b.state(st, now), Failures: st.failures}
+		if status.State == BreakerOpen {
+			until := st.openedAt.Add(b.cooldown)
+			status.OpenUntil = &until
+		}
+		result = append(result, status)
+	}
+	slices.SortFunc(result, func(a, b BreakerStatus) int { return cmp.Compare(a.Destination, b.Destination) })
+	return result
+}
+
+// state reports the circuit state of st at now. The caller must hold b.mu.
+func (b *circuitBreakers) state(st *breakerState, now time.Time) string {
+	switch {
+	case b.threshold == 0 || st.failures < b.threshold:
+		return BreakerClosed
+	case now.Before(st.openedAt.Add(b.cooldown)):
+		return BreakerOpen
+	default:
+		return BreakerHalfOpen
+	}
+}
+
+// allow returns ErrCircuitOpen, naming the destination, if any target's circuit is open or is
+// half-open with a trial run already in flight. Otherwise the run becomes the trial run of every
+// half-open target, which allow returns as probes; the caller must pass them to endProbes once the
+// attempt is over.
+func (b *circuitBreakers) allow(targets []target) (probes []string, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	for _, t := range targets {
+		name := t.dst.Info().Name
+		st, ok := b.states[name]
+		if !ok {
+			continue
+		}
+		switch b.state(st, now) {
+		case BreakerOpen:
+			return nil, fmt.Errorf("destination %s: %w after %d consecutive load failures, retry after %s",
+				name, ErrCircuitOpen, st.failures, st.openedAt.Add(b.cooldown).Format(time.RFC3339))
+		case BreakerHalfOpen:
+			if st.probing {
+				return nil, fmt.Errorf("destination %s: %w after %d consecutive load failures, a trial run is in flight",
+					name, ErrCircuitOpen, st.failures)
+			}
+			probes = append(probes, name)
+		}
+	}
+	for _, name := range probes {
+		b.states[name].probing = true
+	}
+	return probes, nil
+}
+
+// endProbes clears the trial runs allow handed out, including those whose load never reached
+// record, such as an attempt that failed during extraction or was cancelled.
+func (b *circuitBreakers) endProbes(probes []string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, name := range probes {
+		b.states[name].probing = false
+	}
+}
+
+// record notes the outcome of a load into the named destination.
+func (b *circuitBreakers) record(name string, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	st, ok := b.states[name]
+	if !ok {
+		st = &breakerState{}
+		b.states[name] = st
+	}
+	st.probing = false
+	if err == nil {
+		st.failures = 0
+		return
+	}
+	st.failures++
+	if b.threshold > 0 && st.failures >= b.threshold {
+		// reaching the threshold, or failing the trial run after a cooldown, (re)opens the circuit
+		st.openedAt = time.Now()
+	}
+}
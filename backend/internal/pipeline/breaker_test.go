@@ -0,0 +1,136 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"job-hunt/backend/internal/connectors"
+)
+
+var errLoad = errors.New("load failed")
+
+// nullTargets is a plan's target list holding just the null destination.
+var nullTargets = []target{{dst: &connectors.NullDestination{}}}
+
+// newBreakers returns breakers with the given threshold and an hour's cooldown.
+func newBreakers(threshold int) *circuitBreakers {
+	b := newCircuitBreakers()
+	b.threshold, b.cooldown = threshold, time.Hour
+	return b
+}
+
+// cool moves the open circuit of the null destination past its cooldown.
+func cool(b *circuitBreakers) {
+	b.states["null"].openedAt = time.Now().Add(-b.cooldown)
+}
+
+// stateOf returns the reported state of the null destination's circuit.
+func stateOf(b *circuitBreakers) string {
+	return b.state(b.states["null"], time.Now())
+}
+
+func TestBreakerThreshold(t *testing.T) {
+	b := newBreakers(3)
+	for i := range 2 {
+		b.record("null", errLoad)
+		if _, err := b.allow(nullTargets); err != nil {
+			t.Fatalf("allow after %d failures: %v", i+1, err)
+		}
+	}
+	b.record("null", errLoad)
+	if _, err := b.allow(nullTargets); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("allow at the threshold = %v, want ErrCircuitOpen", err)
+	}
+	if got := stateOf(b); got != BreakerOpen {
+		t.Fatalf("state = %s, want open", got)
+	}
+
+	// a success before the threshold starts the count again
+	b = newBreakers(3)
+	b.record("null", errLoad)
+	b.record("null", errLoad)
+	b.record("null", nil)
+	b.record("null", errLoad)
+	if _, err := b.allow(nullTargets); err != nil {
+		t.Fatalf("allow after a reset: %v", err)
+	}
+}
+
+func TestBreakerDisabled(t *testing.T) {
+	b := newBreakers(0)
+	for range 10 {
+		b.record("null", errLoad)
+	}
+	if _, err := b.allow(nullTargets); err != nil {
+		t.Fatalf("allow with breakers disabled: %v", err)
+	}
+}
+
+func TestBreakerHalfOpen(t *testing.T) {
+	b := newBreakers(1)
+	b.record("null", errLoad)
+	cool(b)
+	if got := stateOf(b); got != BreakerHalfOpen {
+		t.Fatalf("state after the cooldown = %s, want half-open", got)
+	}
+
+	probes, err := b.allow(nullTargets)
+	if err != nil || len(probes) != 1 {
+		t.Fatalf("first allow when half-open = %v %v, want one probe", probes, err)
+	}
+	// only the trial run is let through
+	if _, err := b.allow(nullTargets); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("second allow with a trial in flight = %v, want ErrCircuitOpen", err)
+	}
+
+	// a trial that never loaded, e.g. cancelled, frees the circuit for another
+	b.endProbes(probes)
+	probes, err = b.allow(nullTargets)
+	if err != nil || len(probes) != 1 {
+		t.Fatalf("allow after an abandoned trial = %v %v", probes, err)
+	}
+
+	// a failed trial opens the circuit for another cooldown
+	b.record("null", errLoad)
+	b.endProbes(probes)
+	if got := stateOf(b); got != BreakerOpen {
+		t.Fatalf("state after a failed trial = %s, want open", got)
+	}
+
+	// a successful trial closes it
+	cool(b)
+	probes, _ = b.allow(nullTargets)
+	b.record("null", nil)
+	b.endProbes(probes)
+	if got := stateOf(b); got != BreakerClosed || b.states["null"].failures != 0 {
+		t.Fatalf("state after a successful trial = %s with %d failures, want closed", got, b.states["null"].failures)
+	}
+	if probes, err := b.allow(nullTargets); err != nil || len(probes) != 0 {
+		t.Fatalf("allow when closed = %v %v, want no probe", probes, err)
+	}
+}
+
+func TestServiceBreaker(t *testing.T) {
+	svc := newFailingService(t)
+	svc.SetCircuitBreaker(2, time.Hour)
+	cfg := testConfig("failing", 20)
+	cfg.DestType = "failing"
+	if err := svc.Create(cfg); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	for range 2 {
+		if res := svc.Run(context.Background(), "failing"); errors.Is(res.Err, ErrCircuitOpen) {
+			t.Fatalf("Run below the threshold short-circuited: %q", res.Error)
+		}
+	}
+	if res := svc.Run(context.Background(), "failing"); !errors.Is(res.Err, ErrCircuitOpen) {
+		t.Fatalf("Run at the threshold = %q, want ErrCircuitOpen", res.Error)
+	}
+	status := svc.Breakers()
+	if len(status) != 1 || status[0].Destination != "failing" || status[0].State != BreakerOpen || status[0].OpenUntil == nil {
+		t.Fatalf("Breakers = %+v", status)
+	}
+}
@@ -0,0 +1,29 @@
+package pipeline
+
+import "sync/atomic"
+
+// RecordCounter counts records moving through a run. It is safe to update from concurrent stages
+// and loaders and to read while the run is in flight. The zero value is a count of 0.
+type RecordCounter struct {
+	n atomic.Int64
+}
+
+// Observe counts one record; it has the signature of a Tee callback, e.g. Tee(in, c.Observe).
+func (c *RecordCounter) Observe(map[string]any) {
+	c.n.Add(1)
+}
+
+// Add adjusts the count by delta and returns the new count.
+func (c *RecordCounter) Add(delta int64) int64 {
+	return c.n.Add(delta)
+}
+
+// Count returns the current count.
+func (c *RecordCounter) Count() int64 {
+	return c.n.Load()
+}
+
+// Reset sets the count back to 0, as every attempt of a run starts from nothing.
+func (c *RecordCounter) Reset() {
+	c.n.Store(0)
+}
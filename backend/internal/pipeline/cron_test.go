@@ -0,0 +1,159 @@
+package pipeline
+
+import (
+	"testing"
+	"time"
+)
+
+// bits returns the set of the given values, as parseCronField builds it.
+func bits(values ...int) uint64 {
+	var set uint64
+	for _, v := range values {
+		set |= 1 << v
+	}
+	return set
+}
+
+func TestParseCronField(t *testing.T) {
+	for _, tc := range []struct {
+		field    string
+		min, max int
+		want     uint64
+	}{
+		{"*", 0, 6, bits(0, 1, 2, 3, 4, 5, 6)},
+		{"5", 0, 59, bits(5)},
+		{"1-4", 0, 59, bits(1, 2, 3, 4)},
+		{"1,3,5", 0, 59, bits(1, 3, 5)},
+		{"*/15", 0, 59, bits(0, 15, 30, 45)},
+		{"10-20/5", 0, 59, bits(10, 15, 20)},
+		{"50/5", 0, 59, bits(50, 55)},
+		{"1-3,10,20-22", 0, 59, bits(1, 2, 3, 10, 20, 21, 22)},
+		{"*/5", 1, 12, bits(1, 6, 11)},
+	} {
+		got, err := parseCronField(tc.field, tc.min, tc.max)
+		if err != nil {
+			t.Fatalf("parseCronField(%q): %v", tc.field, err)
+		}
+		if got != tc.want {
+			t.Fatalf("parseCronField(%q) = %b, want %b", tc.field, got, tc.want)
+		}
+	}
+}
+
+func TestParseCronInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * 32 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"a * * * *",
+		"1- * * * *",
+		"-1 * * * *",
+		"1,,2 * * * *",
+		"@every5m",
+	} {
+		if _, err := parseCron(expr); err == nil {
+			t.Fatalf("parseCron(%q) succeeded", expr)
+		}
+	}
+}
+
+func TestCronMacros(t *testing.T) {
+	for macro, expr := range cronMacros {
+		a, err := parseCron(macro)
+		if err != nil {
+			t.Fatalf("parseCron(%q): %v", macro, err)
+		}
+		b, _ := parseCron(expr)
+		if *a != *b {
+			t.Fatalf("%s parsed as %+v, want %+v", macro, *a, *b)
+		}
+	}
+}
+
+func TestCronDayOfWeek(t *testing.T) {
+	// 7 is Sunday as well as 0
+	sunday, err := parseCron("0 0 * * 7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sunday.matches(time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)) {
+		t.Fatal("day of week 7 does not match a Sunday")
+	}
+
+	for _, tc := range []struct {
+		expr string
+		day  time.Time
+		want bool
+	}{
+		// both restricted: either day matches
+		{"0 0 13 * 5", time.Date(2026, 11, 13, 0, 0, 0, 0, time.UTC), true}, // Friday the 13th
+		{"0 0 13 * 5", time.Date(2026, 10, 13, 0, 0, 0, 0, time.UTC), true}, // a Tuesday
+		{"0 0 13 * 5", time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC), true}, // a Friday
+		{"0 0 13 * 5", time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC), false},
+		// only one restricted: that one decides
+		{"0 0 13 * *", time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC), false},
+		{"0 0 * * 5", time.Date(2026, 10, 13, 0, 0, 0, 0, time.UTC), false},
+		{"0 0 */2 * 5", time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC), false}, // a step from * is unrestricted
+		{"0 0 */2 * 5", time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC), false},
+		{"0 0 */2 * 5", time.Date(2026, 10, 23, 0, 0, 0, 0, time.UTC), true},
+	} {
+		c, err := parseCron(tc.expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := c.matches(tc.day); got != tc.want {
+			t.Fatalf("%q matches %s = %v, want %v", tc.expr, tc.day.Format("Mon Jan 2"), got, tc.want)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	from := time.Date(2026, 10, 15, 10, 7, 30, 0, time.UTC) // a Thursday
+	for _, tc := range []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, 10, 15, 10, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 10, 15, 10, 15, 0, 0, time.UTC)},
+		{"7 * * * *", time.Date(2026, 10, 15, 11, 7, 0, 0, time.UTC)},
+		{"30 9 * * *", time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)},
+		{"0 9-17 * * 1-5", time.Date(2026, 10, 15, 11, 0, 0, 0, time.UTC)},
+		{"0 0 * * 1", time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 * *", time.Date(2026, 10, 31, 0, 0, 0, 0, time.UTC)},
+		{"0 12 1 1 *", time.Date(2027, 1, 1, 12, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	} {
+		c, err := parseCron(tc.expr)
+		if err != nil {
+			t.Fatalf("parseCron(%q): %v", tc.expr, err)
+		}
+		if got := c.next(from); !got.Equal(tc.want) {
+			t.Fatalf("next(%q) = %s, want %s", tc.expr, got, tc.want)
+		}
+	}
+}
+
+func TestCronScheduleValidated(t *testing.T) {
+	svc := newTestService(t, NewMemoryStore())
+	cfg := testConfig("scheduled", 1)
+	cfg.Schedule = "61 * * * *"
+	if err := svc.Create(cfg); err == nil {
+		t.Fatal("Create accepted an invalid schedule")
+	}
+	cfg.Schedule = "@daily"
+	if err := svc.Create(cfg); err != nil {
+		t.Fatalf("Create with a valid schedule: %v", err)
+	}
+}
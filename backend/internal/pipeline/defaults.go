@@ -0,0 +1,64 @@
+package pipeline
+
+import (
+	"fmt"
+	"maps"
+
+	"job-hunt/backend/internal/connectors"
+)
+
+// connectorKey names a connector by type, since a source and a destination may share a name.
+type connectorKey struct {
+	typ  connectors.ConnectorType
+	name string
+}
+
+// SetConnectorDefaults registers config values that every pipeline using the named connector
+// inherits. Keys a pipeline sets itself always win; defaults only fill in the missing ones.
+// Setting defaults again replaces the previous ones, and an empty config removes them.
+func (s *Service) SetConnectorDefaults(typ connectors.ConnectorType, name string, config map[string]string) error {
+	// defaults are kept under the canonical name so pipelines naming the connector by an alias share them
+	switch typ {
+	case connectors.SourceType:
+		src, err := s.registry.SourceByName(name)
+		if err != nil {
+			return err
+		}
+		name = src.Info().Name
+	case connectors.DestinationType:
+		dst, err := s.registry.DestinationByName(name)
+		if err != nil {
+			return err
+		}
+		name = dst.Info().Name
+	default:
+		return fmt.Errorf("unknown connector type %q", typ)
+	}
+	if err := checkConfigMap(fmt.Sprintf("%s %s defaults", typ, name), config); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := connectorKey{typ, name}
+	if len(config) == 0 {
+		delete(s.defaults, key)
+		return nil
+	}
+	s.defaults[key] = maps.Clone(config)
+	return nil
+}
+
+// withConnectorDefaults returns config with the connector's defaults filled in under it. config is
+// returned as is when the connector has no defaults.
+func (s *Service) withConnectorDefaults(typ connectors.ConnectorType, name string, config map[string]string) map[string]string {
+	s.mu.RLock()
+	defaults := s.defaults[connectorKey{typ, name}]
+	s.mu.RUnlock()
+	if len(defaults) == 0 {
+		return config
+	}
+	merged := maps.Clone(defaults)
+	maps.Copy(merged, config)
+	return merged
+}
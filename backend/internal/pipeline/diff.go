@@ -0,0 +1,112 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+)
+
+// FieldDiff is one field that differs between two pipeline configs. Path names it by its JSON
+// field names, such as "destConfig.host" or "destinations[1].type". A or B is omitted when only the
+// other config sets the field.
+type FieldDiff struct {
+	Path string `json:"path"`
+	A    any    `json:"a,omitempty"`
+	B    any    `json:"b,omitempty"`
+}
+
+// Diff compares the stored definitions of pipelines a and b field by field, descending into the
+// connector config maps and the sources and destinations lists, and returns the differences sorted
+// by path. Names are not compared. Secret values are compared as stored but reported as Redacted.
+// A missing pipeline is an ErrPipelineNotFound naming it.
+func (s *Service) Diff(a, b string) ([]FieldDiff, error) {
+	var docs [2]map[string]any
+	var secrets [2]map[string]bool
+	for i, name := range []string{a, b} {
+		cfg, ok, err := s.store.Load(name)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrPipelineNotFound, name)
+		}
+		// the JSON form gives paths their API names and leaves out unset optional fields
+		data, err := json.Marshal(cfg.withDefaults())
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &docs[i]); err != nil {
+			return nil, err
+		}
+		delete(docs[i], "name")
+		secrets[i] = s.secretPaths(cfg)
+	}
+
+	diffs := []FieldDiff{}
+	var walk func(path string, x, y any)
+	walk = func(path string, x, y any) {
+		xm, xIsMap := x.(map[string]any)
+		ym, yIsMap := y.(map[string]any)
+		xs, xIsList := x.([]any)
+		ys, yIsList := y.([]any)
+		// a map or list only one config sets is walked against nothing, so secrets inside it are
+		// still redacted; Config's JSON form never has a field change kind between configs
+		switch {
+		case xIsMap || yIsMap:
+			keys := slices.Collect(maps.Keys(xm))
+			for k := range ym {
+				if _, ok := xm[k]; !ok {
+					keys = append(keys, k)
+				}
+			}
+			slices.Sort(keys)
+			for _, k := range keys {
+				walk(joinPath(path, k), xm[k], ym[k])
+			}
+		case xIsList || yIsList:
+			for i := range max(len(xs), len(ys)) {
+				var xv, yv any
+				if i < len(xs) {
+					xv = xs[i]
+				}
+				if i < len(ys) {
+					yv = ys[i]
+				}
+				walk(fmt.Sprintf("%s[%d]", path, i), xv, yv)
+			}
+		case !reflect.DeepEqual(x, y):
+			if secrets[0][path] && x != nil {
+				x = Redacted
+			}
+			if secrets[1][path] && y != nil {
+				y = Redacted
+			}
+			diffs = append(diffs, FieldDiff{Path: path, A: x, B: y})
+		}
+	}
+	walk("", docs[0], docs[1])
+	return diffs, nil
+}
+
+// secretPaths returns the diff paths of the config values redact would hide.
+func (s *Service) secretPaths(cfg Config) map[string]bool {
+	paths := map[string]bool{}
+	for _, c := range connectorConfigs(s.registry, &cfg) {
+		for key, value := range *c.config {
+			if value != "" && !isEnvReference(value) && isSecret(c.fields, key) {
+				paths[joinPath(c.label, key)] = true
+			}
+		}
+	}
+	return paths
+}
+
+// joinPath appends a field name to a diff path.
+func joinPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
@@ -0,0 +1,75 @@
+package pipeline
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	t.Setenv("PIPELINE_SECRET_PG_PASSWORD", "from-env")
+	svc := newTestService(t, NewMemoryStore())
+	a := secretConfig("a")
+	b := secretConfig("b")
+	b.SourceConfig["password"] = "changed"
+	b.DestConfig["host"] = "warehouse-2"
+	a.SourceConfig["recordCount"] = "10"
+	b.DestConfig["schema"] = "public"
+	b.Schedule = "@daily"
+	b.Destinations = []DestConfig{{Type: "null", Config: map[string]string{"token": "s3cret"}}}
+	for _, cfg := range []Config{a, b} {
+		if err := svc.Create(cfg); err != nil {
+			t.Fatalf("Create(%s): %v", cfg.Name, err)
+		}
+	}
+
+	diffs, err := svc.Diff("a", "b")
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+	want := []FieldDiff{
+		{Path: "destConfig.host", A: "warehouse", B: "warehouse-2"},
+		{Path: "destConfig.schema", B: "public"},
+		{Path: "destinations[0].config.token", B: Redacted},
+		{Path: "destinations[0].type", B: "null"},
+		{Path: "schedule", B: "@daily"},
+		{Path: "sourceConfig.password", A: Redacted, B: Redacted},
+		{Path: "sourceConfig.recordCount", A: "10"},
+	}
+	if !reflect.DeepEqual(diffs, want) {
+		t.Fatalf("Diff =\n%+v\nwant\n%+v", diffs, want)
+	}
+
+	// the reverse swaps the sides
+	diffs, err = svc.Diff("b", "a")
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+	if len(diffs) != len(want) || diffs[0].A != "warehouse-2" || diffs[0].B != "warehouse" {
+		t.Fatalf("reverse Diff = %+v", diffs)
+	}
+
+	// names are not compared, and identical secrets are not reported
+	c := secretConfig("c")
+	c.SourceConfig["recordCount"] = "10"
+	if err := svc.Create(c); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if diffs, err := svc.Diff("a", "c"); err != nil || len(diffs) != 0 {
+		t.Fatalf("Diff of identical definitions = %+v %v", diffs, err)
+	}
+}
+
+func TestDiffMissing(t *testing.T) {
+	svc := newTestService(t, NewMemoryStore())
+	if err := svc.Create(testConfig("a", 1)); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	for _, pair := range [][2]string{{"a", "nope"}, {"nope", "a"}} {
+		_, err := svc.Diff(pair[0], pair[1])
+		if !errors.Is(err, ErrPipelineNotFound) || !strings.Contains(err.Error(), "nope") {
+			t.Fatalf("Diff(%s, %s) = %v, want ErrPipelineNotFound naming nope", pair[0], pair[1], err)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package pipeline
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"job-hunt/backend/internal/connectors"
+)
+
+// encryptedPrefix marks a config value sealed by EncryptedStore.
+const encryptedPrefix = "enc:v1:"
+
+// EncryptionKeySize is the length of a store encryption key in bytes (AES-256).
+const EncryptionKeySize = 32
+
+// errWrongKey is reported when no configured key opens a sealed value.
+var errWrongKey = errors.New("no configured STORE_ENCRYPTION_KEY can decrypt it; the key is wrong or the value is corrupted")
+
+// EncryptedStore seals secret connector config values before they reach the underlying store and
+// opens them again on load, leaving every other value readable. Each value is encrypted under its
+// own random data key, which is in turn encrypted ("wrapped") under the store key, so rotating the
+// store key only means rewrapping the data keys.
+type EncryptedStore struct {
+	Store
+	registry *connectors.Registry
+	// keys[0] seals new values; every key is tried when opening, so a previous key can still be read
+	keys []cipher.AEAD
+}
+
+// NewEncryptedStore wraps store. The first key encrypts; any further keys are previous keys that
+// are only used to decrypt until Reencrypt has resealed everything under the first.
+func NewEncryptedStore(store Store, reg *connectors.Registry, keys ...[]byte) (*EncryptedStore, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("at least one encryption key is required")
+	}
+	e := &EncryptedStore{Store: store, registry: reg}
+	for _, key := range keys {
+		if len(key) != EncryptionKeySize {
+			return nil, fmt.Errorf("encryption key must be %d bytes, got %d", EncryptionKeySize, len(key))
+		}
+		aead, err := newGCM(key)
+		if err != nil {
+			return nil, err
+		}
+		e.keys = append(e.keys, aead)
+	}
+	return e, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (e *EncryptedStore) Save(cfg Config) error {
+	sealed, err := rewriteConfigs(e.registry, cfg, func(c connectorConfig, key, value string) (string, error) {
+		if value == "" || strings.HasPrefix(value, encryptedPrefix) || isEnvReference(value) || !isSecret(c.fields, key) {
+			return value, nil
+		}
+		return e.seal(key, value)
+	})
+	if err != nil {
+		return fmt.Errorf("encrypt pipeline %s: %w", cfg.Name, err)
+	}
+	return e.Store.Save(sealed)
+}
+
+func (e *EncryptedStore) Load(name string) (Config, bool, error) {
+	cfg, ok, err := e.Store.Load(name)
+	if err != nil || !ok {
+		return cfg, ok, err
+	}
+	if cfg, _, err = e.open(cfg); err != nil {
+		return Config{}, false, err
+	}
+	return cfg, true, nil
+}
+
+func (e *EncryptedStore) All() ([]Config, error) {
+	configs, err := e.Store.All()
+	if err != nil {
+		return nil, err
+	}
+	for i, cfg := range configs {
+		if configs[i], _, err = e.open(cfg); err != nil {
+			return nil, err
+		}
+	}
+	return configs, nil
+}
+
+// Reencrypt reseals every definition that holds a plaintext secret or a value sealed under a
+// previous key. Run at startup, it also surfaces a wrong key before any request is served.
+func (e *EncryptedStore) Reencrypt() error {
+	configs, err := e.Store.All()
+	if err != nil {
+		return err
+	}
+	for _, cfg := range configs {
+		opened, stale, err := e.open(cfg)
+		if err != nil {
+			return err
+		}
+		if !stale {
+			continue
+		}
+		if err := e.Save(opened); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// open decrypts the sealed values of cfg. stale reports that the definition should be resealed:
+// it holds a secret in plaintext or a value sealed under a previous key.
+func (e *EncryptedStore) open(cfg Config) (opened Config, stale bool, err error) {
+	opened, err = rewriteConfigs(e.registry, cfg, func(c connectorConfig, key, value string) (string, error) {
+		sealed, ok := strings.CutPrefix(value, encryptedPrefix)
+		if !ok {
+			if value != "" && !isEnvReference(value) && isSecret(c.fields, key) {
+				stale = true
+			}
+			return value, nil
+		}
+		plain, keyIndex, err := e.unseal(key, sealed)
+		if err != nil {
+			return "", fmt.Errorf("decrypt %s %s of pipeline %s: %w", c.label, key, cfg.Name, err)
+		}
+		if keyIndex > 0 {
+			stale = true
+		}
+		return plain, nil
+	})
+	return opened, stale, err
+}
+
+// seal encrypts value under a fresh data key and wraps the data key under the current store key.
+// The layout is wrapNonce | wrappedKey | nonce | ciphertext, with the config key name as
+// additional data so a sealed value cannot be moved to another key.
+func (e *EncryptedStore) seal(name, value string) (string, error) {
+	dataKey := make([]byte, EncryptionKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+	data, err := newGCM(dataKey)
+	if err != nil {
+		return "", err
+	}
+	kek := e.keys[0]
+	out := make([]byte, kek.NonceSize(), kek.NonceSize()+EncryptionKeySize+kek.Overhead()+data.NonceSize()+len(value)+data.Overhead())
+	if _, err := rand.Read(out); err != nil {
+		return "", err
+	}
+	out = kek.Seal(out, out, dataKey, nil)
+	nonce := make([]byte, data.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	out = append(out, nonce...)
+	out = data.Seal(out, nonce, []byte(value), []byte(name))
+	return encryptedPrefix + base64.RawStdEncoding.EncodeToString(out), nil
+}
+
+// unseal reverses seal, returning the index of the store key that unwrapped the data key.
+func (e *EncryptedStore) unseal(name, sealed string) (string, int, error) {
+	raw, err := base64.RawStdEncoding.DecodeString(sealed)
+	if err != nil {
+		return "", 0, errors.New("malformed encrypted value")
+	}
+	for i, kek := range e.keys {
+		wrapped := kek.NonceSize() + EncryptionKeySize + kek.Overhead()
+		if len(raw) < wrapped {
+			return "", 0, errors.New("malformed encrypted value")
+		}
+		dataKey, err := kek.Open(nil, raw[:kek.NonceSize()], raw[kek.NonceSize():wrapped], nil)
+		if err != nil {
+			continue
+		}
+		data, err := newGCM(dataKey)
+		if err != nil {
+			return "", 0, err
+		}
+		rest := raw[wrapped:]
+		if len(rest) < data.NonceSize() {
+			return "", 0, errors.New("malformed encrypted value")
+		}
+		plain, err := data.Open(nil, rest[:data.NonceSize()], rest[data.NonceSize():], []byte(name))
+		if err != nil {
+			return "", 0, errWrongKey
+		}
+		return string(plain), i, nil
+	}
+	return "", 0, errWrongKey
+}
+
+// CheckUnencrypted fails if store holds values sealed by EncryptedStore, which cannot be read
+// without STORE_ENCRYPTION_KEY.
+func CheckUnencrypted(store Store, reg *connectors.Registry) error {
+	configs, err := store.All()
+	if err != nil {
+		return err
+	}
+	for _, cfg := range configs {
+		_, err := rewriteConfigs(reg, cfg, func(c connectorConfig, key, value string) (string, error) {
+			if strings.HasPrefix(value, encryptedPrefix) {
+				return "", fmt.Errorf("%s %s of pipeline %s is encrypted but STORE_ENCRYPTION_KEY is not set", c.label, key, cfg.Name)
+			}
+			return value, nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
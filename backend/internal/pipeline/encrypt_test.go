@@ -0,0 +1,137 @@
+package pipeline
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// testKey is a fixed store encryption key filled with b.
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, EncryptionKeySize)
+}
+
+// secretConfig is a MySQL to Postgres pipeline with a plaintext password and an env reference.
+func secretConfig(name string) Config {
+	return Config{
+		Name:         name,
+		SourceType:   "mysql",
+		SourceConfig: map[string]string{"host": "db.internal", "port": "3306", "user": "etl", "password": "hunter2", "database": "shop"},
+		DestType:     "postgres",
+		DestConfig:   map[string]string{"host": "warehouse", "port": "5432", "user": "etl", "password": "${PIPELINE_SECRET_PG_PASSWORD}", "database": "analytics"},
+	}
+}
+
+func newEncryptedStore(t *testing.T, store Store, keys ...[]byte) *EncryptedStore {
+	t.Helper()
+	e, err := NewEncryptedStore(store, newTestService(t, store).registry, keys...)
+	if err != nil {
+		t.Fatalf("NewEncryptedStore: %v", err)
+	}
+	return e
+}
+
+func TestEncryptedStoreRoundTrip(t *testing.T) {
+	mem := NewMemoryStore()
+	enc := newEncryptedStore(t, mem, testKey(1))
+	if err := enc.Save(secretConfig("orders")); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	raw, _, _ := mem.Load("orders")
+	if got := raw.SourceConfig["password"]; !strings.HasPrefix(got, encryptedPrefix) || strings.Contains(got, "hunter2") {
+		t.Fatalf("stored password = %q, want it sealed", got)
+	}
+	if raw.SourceConfig["host"] != "db.internal" || raw.DestConfig["password"] != "${PIPELINE_SECRET_PG_PASSWORD}" {
+		t.Fatalf("non-secret values were changed: %v %v", raw.SourceConfig, raw.DestConfig)
+	}
+
+	cfg, ok, err := enc.Load("orders")
+	if err != nil || !ok || cfg.SourceConfig["password"] != "hunter2" {
+		t.Fatalf("Load = %v %v %v, want the plaintext password", cfg.SourceConfig, ok, err)
+	}
+	all, err := enc.All()
+	if err != nil || len(all) != 1 || all[0].SourceConfig["password"] != "hunter2" {
+		t.Fatalf("All = %v %v", all, err)
+	}
+}
+
+func TestEncryptedStoreRotation(t *testing.T) {
+	mem := NewMemoryStore()
+	if err := newEncryptedStore(t, mem, testKey(1)).Save(secretConfig("orders")); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	before, _, _ := mem.Load("orders")
+
+	// the new key first, the previous one after it, as STORE_PREVIOUS_ENCRYPTION_KEY gives it
+	rotating := newEncryptedStore(t, mem, testKey(2), testKey(1))
+	if err := rotating.Reencrypt(); err != nil {
+		t.Fatalf("Reencrypt: %v", err)
+	}
+	after, _, _ := mem.Load("orders")
+	if after.SourceConfig["password"] == before.SourceConfig["password"] {
+		t.Fatal("Reencrypt left the value sealed under the previous key")
+	}
+
+	if cfg, _, err := newEncryptedStore(t, mem, testKey(2)).Load("orders"); err != nil || cfg.SourceConfig["password"] != "hunter2" {
+		t.Fatalf("Load with the new key alone = %v %v", cfg.SourceConfig, err)
+	}
+	if _, _, err := newEncryptedStore(t, mem, testKey(1)).Load("orders"); !errors.Is(err, errWrongKey) {
+		t.Fatalf("Load with the retired key = %v, want errWrongKey", err)
+	}
+}
+
+func TestEncryptedStoreWrongKey(t *testing.T) {
+	mem := NewMemoryStore()
+	if err := newEncryptedStore(t, mem, testKey(1)).Save(secretConfig("orders")); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	wrong := newEncryptedStore(t, mem, testKey(3))
+	if _, _, err := wrong.Load("orders"); !errors.Is(err, errWrongKey) {
+		t.Fatalf("Load = %v, want errWrongKey", err)
+	}
+	if err := wrong.Reencrypt(); !errors.Is(err, errWrongKey) {
+		t.Fatalf("Reencrypt = %v, want errWrongKey", err)
+	}
+	if err := CheckUnencrypted(mem, wrong.registry); err == nil {
+		t.Fatal("CheckUnencrypted accepted a store holding sealed values")
+	}
+	if _, err := NewEncryptedStore(mem, wrong.registry, []byte("short")); err == nil {
+		t.Fatal("NewEncryptedStore accepted a short key")
+	}
+}
+
+// TestSealedLookingInput checks that a client cannot submit a value that looks sealed, which would
+// be stored as is and then fail to decrypt on every load and at startup.
+func TestSealedLookingInput(t *testing.T) {
+	t.Setenv("PIPELINE_SECRET_PG_PASSWORD", "from-env")
+	mem := NewMemoryStore()
+	enc := newEncryptedStore(t, mem, testKey(1))
+	svc := newTestService(t, enc)
+	if err := svc.Create(secretConfig("orders")); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	for _, key := range []string{"password", "host"} {
+		cfg := secretConfig("forged-" + key)
+		cfg.SourceConfig[key] = encryptedPrefix + "x"
+		if err := svc.Create(cfg); !errors.Is(err, ErrValidation) {
+			t.Fatalf("Create with a sealed-looking %s = %v, want ErrValidation", key, err)
+		}
+		cfg.Name = "orders"
+		if err := svc.Update(cfg); !errors.Is(err, ErrValidation) {
+			t.Fatalf("Update with a sealed-looking %s = %v, want ErrValidation", key, err)
+		}
+	}
+
+	if _, _, err := svc.List(ListOptions{}); err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if err := enc.Reencrypt(); err != nil {
+		t.Fatalf("Reencrypt: %v", err)
+	}
+	if err := CheckUnencrypted(NewMemoryStore(), enc.registry); err != nil {
+		t.Fatalf("CheckUnencrypted of a plaintext store: %v", err)
+	}
+}
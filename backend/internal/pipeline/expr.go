@@ -0,0 +1,106 @@
+package pipeline
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+)
+
+// expression is one step of the "expressions" transform, decoded from transformConfig.
+type expression struct {
+	Op        string   `json:"op"`
+	Field     string   `json:"field,omitempty"`
+	Value     any      `json:"value,omitempty"`
+	From      string   `json:"from,omitempty"`
+	To        string   `json:"to,omitempty"`
+	Fields    []string `json:"fields,omitempty"`
+	Separator string   `json:"separator,omitempty"`
+}
+
+// expressions applies a compiled list of expressions to each record in order.
+type expressions []expression
+
+// newExpressions compiles the JSON array in the transform config's expressions key:
+//
+//	{"op": "set", "field": "f", "value": v}                  sets f to v
+//	{"op": "remove", "field": "f"}                           deletes f
+//	{"op": "copy", "from": "a", "to": "b"}                   copies a to b when a is present
+//	{"op": "concat", "fields": ["a", "b"], "to": "c", "separator": " "}
+//	                                                         joins the fields' values as text into c
+func newExpressions(config map[string]string) (Transform, error) {
+	raw := config["expressions"]
+	if raw == "" {
+		return nil, errors.New("transformConfig expressions is required")
+	}
+	dec := json.NewDecoder(bytes.NewReader([]byte(raw)))
+	dec.DisallowUnknownFields()
+	var exprs expressions
+	if err := dec.Decode(&exprs); err != nil {
+		return nil, fmt.Errorf("expressions must be a JSON array of operations: %w", err)
+	}
+	for i, e := range exprs {
+		if err := e.check(); err != nil {
+			return nil, fmt.Errorf("expression %d: %w", i, err)
+		}
+	}
+	return exprs, nil
+}
+
+// check reports a missing operand for the expression's op.
+func (e expression) check() error {
+	switch e.Op {
+	case "set":
+		if e.Field == "" {
+			return errors.New("set requires field")
+		}
+	case "remove":
+		if e.Field == "" {
+			return errors.New("remove requires field")
+		}
+	case "copy":
+		if e.From == "" || e.To == "" {
+			return errors.New("copy requires from and to")
+		}
+	case "concat":
+		if len(e.Fields) == 0 || e.To == "" {
+			return errors.New("concat requires fields and to")
+		}
+	default:
+		return fmt.Errorf("unknown op %q", e.Op)
+	}
+	return nil
+}
+
+func (exprs expressions) Apply(record map[string]any) (map[string]any, error) {
+	out := maps.Clone(record)
+	if out == nil {
+		out = map[string]any{}
+	}
+	for _, e := range exprs {
+		switch e.Op {
+		case "set":
+			out[e.Field] = e.Value
+		case "remove":
+			delete(out, e.Field)
+		case "copy":
+			if v, ok := out[e.From]; ok {
+				out[e.To] = v
+			}
+		case "concat":
+			parts := make([]string, 0, len(e.Fields))
+			for _, f := range e.Fields {
+				// missing and null fields contribute an empty string
+				if v, ok := out[f]; ok && v != nil {
+					parts = append(parts, fmt.Sprint(v))
+				} else {
+					parts = append(parts, "")
+				}
+			}
+			out[e.To] = strings.Join(parts, e.Separator)
+		}
+	}
+	return out, nil
+}
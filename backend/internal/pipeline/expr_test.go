@@ -0,0 +1,102 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// applyExpressions compiles raw and applies it to record.
+func applyExpressions(t *testing.T, raw string, record map[string]any) map[string]any {
+	t.Helper()
+	tr, err := newExpressions(map[string]string{"expressions": raw})
+	if err != nil {
+		t.Fatalf("newExpressions(%s): %v", raw, err)
+	}
+	out, err := tr.Apply(record)
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	return out
+}
+
+func TestExpressions(t *testing.T) {
+	record := map[string]any{"first": "Ada", "last": "Lovelace", "id": float64(1), "note": nil}
+	for _, tc := range []struct {
+		name, raw string
+		want      map[string]any
+	}{
+		{"set", `[{"op":"set","field":"source","value":"crm"}]`,
+			map[string]any{"first": "Ada", "last": "Lovelace", "id": float64(1), "note": nil, "source": "crm"}},
+		{"set overwrites", `[{"op":"set","field":"id","value":{"n":2}}]`,
+			map[string]any{"first": "Ada", "last": "Lovelace", "id": map[string]any{"n": float64(2)}, "note": nil}},
+		{"set null", `[{"op":"set","field":"first"}]`,
+			map[string]any{"first": nil, "last": "Lovelace", "id": float64(1), "note": nil}},
+		{"remove", `[{"op":"remove","field":"note"},{"op":"remove","field":"absent"}]`,
+			map[string]any{"first": "Ada", "last": "Lovelace", "id": float64(1)}},
+		{"copy", `[{"op":"copy","from":"id","to":"key"},{"op":"copy","from":"absent","to":"other"}]`,
+			map[string]any{"first": "Ada", "last": "Lovelace", "id": float64(1), "note": nil, "key": float64(1)}},
+		{"concat", `[{"op":"concat","fields":["first","note","absent","last"],"to":"name","separator":"|"}]`,
+			map[string]any{"first": "Ada", "last": "Lovelace", "id": float64(1), "note": nil, "name": "Ada|||Lovelace"}},
+		{"concat numbers", `[{"op":"concat","fields":["id","first"],"to":"id"}]`,
+			map[string]any{"first": "Ada", "last": "Lovelace", "id": "1Ada", "note": nil}},
+		{"in order", `[{"op":"concat","fields":["first","last"],"to":"name","separator":" "},{"op":"remove","field":"first"},{"op":"remove","field":"last"},{"op":"copy","from":"name","to":"display"}]`,
+			map[string]any{"id": float64(1), "note": nil, "name": "Ada Lovelace", "display": "Ada Lovelace"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := applyExpressions(t, tc.raw, record); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+	if len(record) != 4 || record["first"] != "Ada" {
+		t.Fatalf("Apply changed its input record: %v", record)
+	}
+	if got := applyExpressions(t, `[{"op":"set","field":"a","value":1}]`, nil); got["a"] != float64(1) {
+		t.Fatalf("Apply to a nil record = %v", got)
+	}
+}
+
+func TestExpressionsInvalid(t *testing.T) {
+	for _, raw := range []string{
+		``,
+		`{}`,
+		`[{"op":"set"}]`,
+		`[{"op":"remove"}]`,
+		`[{"op":"copy","from":"a"}]`,
+		`[{"op":"copy","to":"b"}]`,
+		`[{"op":"concat","to":"c"}]`,
+		`[{"op":"concat","fields":["a"]}]`,
+		`[{"op":"rename","from":"a","to":"b"}]`,
+		`[{"field":"a"}]`,
+		`[{"op":"set","field":"a","typo":1}]`,
+		`[{"op":"set","field":"a"}`,
+	} {
+		if _, err := newExpressions(map[string]string{"expressions": raw}); err == nil {
+			t.Fatalf("newExpressions(%s) succeeded", raw)
+		}
+	}
+}
+
+func TestExpressionsAtCreate(t *testing.T) {
+	svc := newTestService(t, NewMemoryStore())
+	cfg := testConfig("shaped", 3)
+	cfg.Transforms = []string{"expressions"}
+	cfg.TransformConfig = map[string]string{"expressions": `[{"op":"set","field":"a","value":1},{"op":"copy","from":"a"}]`}
+	err := svc.Create(cfg)
+	if !errors.Is(err, ErrValidation) || !strings.Contains(err.Error(), "expression 1") {
+		t.Fatalf("Create = %v, want the malformed expression refused", err)
+	}
+
+	cfg.TransformConfig["expressions"] = `[{"op":"copy","from":"id","to":"key"},{"op":"remove","field":"payload"}]`
+	cfg.QualityRules = []string{"key != null", "payload == null"}
+	if err := svc.Create(cfg); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	res := svc.Run(context.Background(), "shaped")
+	if res.Status != StatusSucceeded || res.Records != 3 {
+		t.Fatalf("Run = %s %q with %d records; the expressions did not apply", res.Status, res.Error, res.Records)
+	}
+}
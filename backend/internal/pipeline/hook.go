@@ -0,0 +1,39 @@
+package pipeline
+
+import "context"
+
+// RunHook checks or adjusts a pipeline definition right before it runs, for example to inject a
+// freshly issued token into a connector config. BeforeRun gets a copy of the stored definition, so
+// its changes apply to this run only. The name and timeout are fixed by then; changing them has no
+// effect. An error aborts the run and becomes the Result's error. The definition a hook hands back
+// is validated again, so one that would be refused at creation fails the run with ErrValidation.
+type RunHook interface {
+	BeforeRun(ctx context.Context, cfg *Config) error
+}
+
+// RunHookFunc adapts a function to RunHook.
+type RunHookFunc func(ctx context.Context, cfg *Config) error
+
+// BeforeRun calls f.
+func (f RunHookFunc) BeforeRun(ctx context.Context, cfg *Config) error { return f(ctx, cfg) }
+
+// AddRunHook registers h to be called before every run. Hooks are called in the order they were
+// added, each seeing the changes of the ones before it, and the first error stops the rest.
+func (s *Service) AddRunHook(h RunHook) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hooks = append(s.hooks, h)
+}
+
+// beforeRun passes cfg through every run hook.
+func (s *Service) beforeRun(ctx context.Context, cfg *Config) error {
+	s.mu.RLock()
+	hooks := s.hooks
+	s.mu.RUnlock()
+	for _, h := range hooks {
+		if err := h.BeforeRun(ctx, cfg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestRunHookChangesApply(t *testing.T) {
+	svc := newTestService(t, NewMemoryStore())
+	svc.AddRunHook(RunHookFunc(func(_ context.Context, cfg *Config) error {
+		cfg.SourceConfig["recordCount"] = "7"
+		return nil
+	}))
+	if err := svc.Create(testConfig("orders", 3)); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if res := svc.Run(context.Background(), "orders"); res.Status != StatusSucceeded || res.Records != 7 {
+		t.Fatalf("Run = %s with %d records %q, want the hook's 7", res.Status, res.Records, res.Error)
+	}
+	// the change applied to that run only
+	if cfg, _ := svc.Get("orders"); cfg.SourceConfig["recordCount"] != "3" {
+		t.Fatalf("stored recordCount = %q", cfg.SourceConfig["recordCount"])
+	}
+}
+
+func TestRunHookCorruptsConfig(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		corrupt func(cfg *Config)
+	}{
+		{"invalid url", func(cfg *Config) { cfg.SourceConfig["url"] = "ftp://api.example.com" }},
+		{"redacted value", func(cfg *Config) { cfg.SourceConfig["token"] = Redacted }},
+		{"config too large", func(cfg *Config) {
+			for i := range maxConfigEntries + 1 {
+				cfg.DestConfig["key"+strconv.Itoa(i)] = "x"
+			}
+		}},
+		{"unknown destination", func(cfg *Config) { cfg.DestType = "nowhere" }},
+		{"negative retries", func(cfg *Config) { cfg.MaxRetries = -1 }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := newTestService(t, NewMemoryStore())
+			svc.AddRunHook(RunHookFunc(func(_ context.Context, cfg *Config) error {
+				tc.corrupt(cfg)
+				return nil
+			}))
+			if err := svc.Create(testConfig("orders", 3)); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			res := svc.Run(context.Background(), "orders")
+			if res.Status != StatusFailed || !errors.Is(res.Err, ErrValidation) {
+				t.Fatalf("Run = %s %q, want a validation failure", res.Status, res.Error)
+			}
+		})
+	}
+}
+
+func TestRunHookError(t *testing.T) {
+	svc := newTestService(t, NewMemoryStore())
+	denied := errors.New("token service unavailable")
+	svc.AddRunHook(RunHookFunc(func(context.Context, *Config) error { return denied }))
+	if err := svc.Create(testConfig("orders", 3)); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if res := svc.Run(context.Background(), "orders"); !errors.Is(res.Err, denied) {
+		t.Fatalf("Run = %s %q, want the hook's error", res.Status, res.Error)
+	}
+}
@@ -0,0 +1,145 @@
+package pipeline
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Finished async jobs are kept for DefaultJobTTL, and at most DefaultMaxFinishedJobs of them at
+// once, unless SetJobRetention changes it.
+const (
+	DefaultJobTTL          = time.Hour
+	DefaultMaxFinishedJobs = 1000
+)
+
+// job tracks an asynchronous run and the means to cancel it.
+type job struct {
+	result Result
+	cancel context.CancelFunc
+}
+
+// RunAsync queues a pipeline run at the pipeline's priority and returns its job ID along with its
+// queue position, 0 when a worker starts it right away. The run keeps ctx's values, such as the
+// request ID, but not its cancellation. Nothing is queued when it fails: ErrPipelineNotFound or the
+// store's error when the pipeline cannot be loaded, and ErrServerBusy when the concurrent run
+// ceiling is reached.
+func (s *Service) RunAsync(ctx context.Context, name string) (id string, position int, err error) {
+	cfg, ok, err := s.store.Load(name)
+	if err != nil {
+		return "", 0, err
+	}
+	if !ok {
+		return "", 0, fmt.Errorf("%w: %s", ErrPipelineNotFound, name)
+	}
+	if !s.admitRun() {
+		return "", 0, ErrServerBusy
+	}
+	id = newJobID()
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pruneJobs(time.Now())
+	j := &job{
+		result: Result{PipelineName: name, Status: StatusQueued, StartedAt: time.Now(), RequestID: RequestID(ctx)},
+		cancel: cancel,
+	}
+	s.jobs[id] = j
+	if s.queue.closed {
+		s.failJob(j, errShuttingDown)
+		return id, 0, nil
+	}
+	return id, s.enqueue(&queuedRun{id: id, name: name, priority: cfg.Priority, ctx: ctx, enqueuedAt: j.result.StartedAt}), nil
+}
+
+// JobStatus returns the current result of an asynchronous run. A job that finished longer ago than
+// the retention TTL, or was evicted to stay under the cap, is reported as not found.
+func (s *Service) JobStatus(id string) (Result, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	j, ok := s.jobs[id]
+	if !ok || (j.finished() && time.Since(j.result.FinishedAt) > s.jobTTL) {
+		return Result{}, false
+	}
+	return j.result, true
+}
+
+// SetJobRetention sets how long finished async jobs stay queryable and how many are kept at once;
+// past the cap the oldest finished jobs are evicted first. Queued and running jobs are never
+// evicted. Values below 1 are treated as 1.
+func (s *Service) SetJobRetention(ttl time.Duration, maxFinished int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobTTL = max(ttl, 1)
+	s.maxJobs = max(maxFinished, 1)
+	s.pruneJobs(time.Now())
+}
+
+// finished reports whether the job's run has ended. The caller must hold s.mu.
+func (j *job) finished() bool {
+	return j.result.Status != StatusQueued && j.result.Status != StatusRunning
+}
+
+// pruneJobs evicts finished jobs past their TTL, then the oldest finished ones over the cap. It runs
+// whenever a job is added or finishes, so the map stays bounded without a background sweep. The
+// caller must hold s.mu.
+func (s *Service) pruneJobs(now time.Time) {
+	var finished []string
+	for id, j := range s.jobs {
+		switch {
+		case !j.finished():
+		case now.Sub(j.result.FinishedAt) > s.jobTTL:
+			delete(s.jobs, id)
+		default:
+			finished = append(finished, id)
+		}
+	}
+	if excess := len(finished) - s.maxJobs; excess > 0 {
+		slices.SortFunc(finished, func(a, b string) int {
+			return s.jobs[a].result.FinishedAt.Compare(s.jobs[b].result.FinishedAt)
+		})
+		for _, id := range finished[:excess] {
+			delete(s.jobs, id)
+		}
+	}
+}
+
+// CancelJob cancels the context of a running asynchronous job, or takes a queued one off the queue.
+func (s *Service) CancelJob(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[id]
+	if !ok {
+		return errors.New("job not found")
+	}
+	if s.dequeue(id) {
+		s.failJob(j, errors.New("cancelled while queued"))
+		return nil
+	}
+	if j.result.Status != StatusRunning {
+		return errors.New("job is not running")
+	}
+	j.cancel()
+	return nil
+}
+
+// failJob ends a job that never ran, giving back its run slot. The caller must hold s.mu.
+func (s *Service) failJob(j *job, err error) {
+	s.releaseRun()
+	j.cancel()
+	j.result.Status = StatusFailed
+	j.result.fail(err)
+	j.result.FinishedAt = time.Now()
+}
+
+// newJobID generates a random identifier for asynchronous runs.
+func newJobID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,78 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// runJob starts an async run of name and waits for it to finish.
+func runJob(t *testing.T, svc *Service, name string) string {
+	t.Helper()
+	id, _, err := svc.RunAsync(context.Background(), name)
+	if err != nil {
+		t.Fatalf("RunAsync: %v", err)
+	}
+	if res := waitJob(t, svc, id); res.Status != StatusSucceeded {
+		t.Fatalf("job = %s %q", res.Status, res.Error)
+	}
+	return id
+}
+
+func TestJobRetentionCap(t *testing.T) {
+	svc := newTestService(t, NewMemoryStore())
+	svc.SetJobRetention(time.Hour, 2)
+	if err := svc.Create(testConfig("orders", 1)); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	var ids []string
+	for range 4 {
+		ids = append(ids, runJob(t, svc, "orders"))
+	}
+	// each job finishing past the cap evicts the oldest finished one
+	for i, id := range ids {
+		_, ok := svc.JobStatus(id)
+		if want := i >= 2; ok != want {
+			t.Errorf("job %d found = %v, want %v", i, ok, want)
+		}
+	}
+}
+
+func TestJobRetentionTTL(t *testing.T) {
+	svc := newTestService(t, NewMemoryStore())
+	svc.SetJobRetention(20*time.Millisecond, 100)
+	if err := svc.Create(testConfig("orders", 1)); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	first := runJob(t, svc, "orders")
+	time.Sleep(50 * time.Millisecond)
+	if _, ok := svc.JobStatus(first); ok {
+		t.Fatal("expired job still found")
+	}
+	runJob(t, svc, "orders")
+	svc.mu.RLock()
+	defer svc.mu.RUnlock()
+	if _, ok := svc.jobs[first]; ok {
+		t.Fatal("expired job not evicted when the next job was queued")
+	}
+}
+
+func TestRunAsyncMissingPipeline(t *testing.T) {
+	svc := newTestService(t, NewMemoryStore())
+	svc.SetMaxConcurrentRuns(1)
+	if _, _, err := svc.RunAsync(context.Background(), "nope"); !errors.Is(err, ErrPipelineNotFound) {
+		t.Fatalf("RunAsync = %v, want ErrPipelineNotFound", err)
+	}
+	// nothing was queued and no run slot was taken
+	if n := svc.admitted.Load(); n != 0 {
+		t.Fatalf("%d run slots taken", n)
+	}
+	svc.mu.RLock()
+	defer svc.mu.RUnlock()
+	if len(svc.jobs) != 0 {
+		t.Fatalf("%d jobs recorded", len(svc.jobs))
+	}
+}
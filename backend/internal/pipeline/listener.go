@@ -0,0 +1,45 @@
+package pipeline
+
+import (
+	"context"
+	"log/slog"
+)
+
+// ResultListener is told about every finished run, for plugins such as metrics exporters or ticket
+// updaters. OnResult is called on the run's goroutine once the result is in the history, so it
+// should hand slow work off rather than hold up the run.
+type ResultListener interface {
+	OnResult(ctx context.Context, name string, r Result)
+}
+
+// ResultListenerFunc adapts a function to ResultListener.
+type ResultListenerFunc func(ctx context.Context, name string, r Result)
+
+// OnResult calls f.
+func (f ResultListenerFunc) OnResult(ctx context.Context, name string, r Result) { f(ctx, name, r) }
+
+// AddResultListener registers l to be called after every run. Listeners are called in the order
+// they were added.
+func (s *Service) AddResultListener(l ResultListener) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners = append(s.listeners, l)
+}
+
+// notifyListeners passes res to every listener. A panicking listener is logged and skipped so it
+// neither fails the run nor keeps later listeners from being called.
+func (s *Service) notifyListeners(ctx context.Context, res Result) {
+	s.mu.RLock()
+	listeners := s.listeners
+	s.mu.RUnlock()
+	for _, l := range listeners {
+		func() {
+			defer func() {
+				if p := recover(); p != nil {
+					slog.ErrorContext(ctx, "result listener panicked", "pipeline", res.PipelineName, "panic", p)
+				}
+			}()
+			l.OnResult(ctx, res.PipelineName, res)
+		}()
+	}
+}
@@ -0,0 +1,75 @@
+package pipeline
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Metrics accumulates execution counters exposed in the Prometheus text format.
+type Metrics struct {
+	mu      sync.Mutex
+	runs    map[runLabels]int64
+	records map[string]int64
+}
+
+// runLabels identifies a pipeline_runs_total series.
+type runLabels struct {
+	pipeline string
+	status   string
+}
+
+func newMetrics() *Metrics {
+	return &Metrics{runs: map[runLabels]int64{}, records: map[string]int64{}}
+}
+
+// observe counts a finished run.
+func (m *Metrics) observe(res Result) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.runs[runLabels{pipeline: res.PipelineName, status: res.Status}]++
+	m.records[res.PipelineName] += int64(res.Records)
+}
+
+// WritePrometheus renders all counters in the Prometheus text exposition format.
+func (m *Metrics) WritePrometheus(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	runKeys := make([]runLabels, 0, len(m.runs))
+	for k := range m.runs {
+		runKeys = append(runKeys, k)
+	}
+	sort.Slice(runKeys, func(i, j int) bool {
+		if runKeys[i].pipeline != runKeys[j].pipeline {
+			return runKeys[i].pipeline < runKeys[j].pipeline
+		}
+		return runKeys[i].status < runKeys[j].status
+	})
+	names := make([]string, 0, len(m.records))
+	for name := range m.records {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("# HELP pipeline_runs_total Pipeline runs by final status.\n")
+	b.WriteString("# TYPE pipeline_runs_total counter\n")
+	for _, k := range runKeys {
+		fmt.Fprintf(&b, "pipeline_runs_total{pipeline=\"%s\",status=\"%s\"} %d\n", escapeLabel(k.pipeline), escapeLabel(k.status), m.runs[k])
+	}
+	b.WriteString("# HELP pipeline_records_total Records loaded by pipeline.\n")
+	b.WriteString("# TYPE pipeline_records_total counter\n")
+	for _, name := range names {
+		fmt.Fprintf(&b, "pipeline_records_total{pipeline=\"%s\"} %d\n", escapeLabel(name), m.records[name])
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// escapeLabel escapes a label value per the exposition format.
+func escapeLabel(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
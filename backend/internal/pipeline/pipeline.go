@@ -3,142 +3,1639 @@ package pipeline
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"job-hunt/backend/internal/connectors"
 )
 
+const (
+	// maxHistory bounds the number of results retained per pipeline.
+	maxHistory = 50
+	// maxRetryBackoff caps the exponential delay between retry attempts.
+	maxRetryBackoff = time.Minute
+	// maxDeadLetters bounds the rejected records kept on a single result.
+	maxDeadLetters = 100
+	// schemaSampleSize is the number of source records inferred from by the schema check.
+	schemaSampleSize = 20
+	// schemaCheckTimeout bounds the sampling done by the schema check.
+	schemaCheckTimeout = 10 * time.Second
+	// maxConfigEntries, maxConfigKeyLength, and maxConfigValueLength bound each connector config map.
+	maxConfigEntries     = 64
+	maxConfigKeyLength   = 64
+	maxConfigValueLength = 4096
+	// maxBufferSize bounds Config.BufferSize so a pipeline cannot buffer unbounded records in memory.
+	maxBufferSize = 10000
+)
+
+var (
+	// errCancelledByUser is the cancellation cause recorded by Cancel.
+	errCancelledByUser = errors.New("cancelled by user")
+	// errShuttingDown is the cancellation cause recorded when Shutdown runs out of grace time.
+	errShuttingDown = errors.New("cancelled by server shutdown")
+
+	// ErrPipelineExists is returned by Create when the name is already taken.
+	ErrPipelineExists = errors.New("pipeline already exists")
+	// ErrPipelineNotFound is returned for operations on a name with no stored definition.
+	ErrPipelineNotFound = errors.New("pipeline not found")
+	// ErrAlreadyRunning is the error of a run started while the pipeline has one in flight.
+	ErrAlreadyRunning = errors.New("pipeline already running")
+	// ErrNotRunning is returned by Cancel for a pipeline with no run in flight.
+	ErrNotRunning = errors.New("pipeline is not running")
+	// ErrValidation marks every error describing an invalid pipeline definition. The underlying
+	// error, such as connectors.ErrUnknownSource, stays reachable through errors.Is.
+	ErrValidation = errors.New("invalid pipeline definition")
+)
+
+// validationError reports its cause's message while matching both ErrValidation and the cause.
+type validationError struct{ err error }
+
+func (e validationError) Error() string { return e.err.Error() }
+
+func (e validationError) Unwrap() []error { return []error{ErrValidation, e.err} }
+
 // Config defines pipeline pairing between source and destination.
 type Config struct {
-	Name         string            `json:"name"`
-	SourceType   string            `json:"sourceType"`
-	SourceConfig map[string]string `json:"sourceConfig"`
-	DestType     string            `json:"destType"`
-	DestConfig   map[string]string `json:"destConfig"`
+	Name            string            `json:"name"`
+	SourceType      string            `json:"sourceType"`
+	SourceConfig    map[string]string `json:"sourceConfig"`
+	DestType        string            `json:"destType"`
+	DestConfig      map[string]string `json:"destConfig"`
+	Transforms      []string          `json:"transforms,omitempty"`
+	TransformConfig map[string]string `json:"transformConfig,omitempty"`
+	MaxRetries      int               `json:"maxRetries,omitempty"`
+	RetryBackoffMs  int               `json:"retryBackoffMs,omitempty"`
+	TimeoutSeconds  int               `json:"timeoutSeconds,omitempty"`
+	Destinations    []DestConfig      `json:"destinations,omitempty"`
+	Sources         []SourceConfig    `json:"sources,omitempty"`
+	Mapping         map[string]string `json:"mapping,omitempty"`
+	DropUnmapped    bool              `json:"dropUnmapped,omitempty"`
+	DedupeKey       string            `json:"dedupeKey,omitempty"`
+	Schedule        string            `json:"schedule,omitempty"`
+	WebhookURL      string            `json:"webhookUrl,omitempty"`
+	BufferSize      int               `json:"bufferSize,omitempty"`
+	CheckSchema     bool              `json:"checkSchema,omitempty"`
+	MaxRecords      int               `json:"maxRecords,omitempty"`
+	ParallelLoaders int               `json:"parallelLoaders,omitempty"`
+	OnError         string            `json:"onError,omitempty"`
+	QualityRules    []string          `json:"qualityRules,omitempty"`
+	QualityPolicy   string            `json:"qualityPolicy,omitempty"`
+	// Priority orders queued runs from 0 (the default) to MaxPriority; higher runs start first.
+	Priority int `json:"priority,omitempty"`
+	// Enabled pauses the schedule when false; unset means enabled.
+	Enabled *bool `json:"enabled"`
+}
+
+// IsEnabled reports whether the scheduler may run the pipeline.
+func (c Config) IsEnabled() bool {
+	return c.Enabled == nil || *c.Enabled
 }
 
+// withDefaults fills in optional fields so stored and returned configs are explicit.
+func (c Config) withDefaults() Config {
+	if c.Enabled == nil {
+		enabled := true
+		c.Enabled = &enabled
+	}
+	return c
+}
+
+// clone returns a deep copy of c, so changes to it cannot reach a stored definition.
+func (c Config) clone() Config {
+	c.SourceConfig = maps.Clone(c.SourceConfig)
+	c.DestConfig = maps.Clone(c.DestConfig)
+	c.Transforms = slices.Clone(c.Transforms)
+	c.TransformConfig = maps.Clone(c.TransformConfig)
+	c.Mapping = maps.Clone(c.Mapping)
+	c.QualityRules = slices.Clone(c.QualityRules)
+	c.Sources = slices.Clone(c.Sources)
+	for i := range c.Sources {
+		c.Sources[i].Config = maps.Clone(c.Sources[i].Config)
+	}
+	c.Destinations = slices.Clone(c.Destinations)
+	for i := range c.Destinations {
+		c.Destinations[i].Config = maps.Clone(c.Destinations[i].Config)
+	}
+	if c.Enabled != nil {
+		enabled := *c.Enabled
+		c.Enabled = &enabled
+	}
+	return c
+}
+
+// SourceConfig names an additional source of a merge pipeline.
+type SourceConfig struct {
+	Type   string            `json:"type"`
+	Config map[string]string `json:"config"`
+}
+
+// DestConfig names an additional destination of a fan-out pipeline.
+type DestConfig struct {
+	Type   string            `json:"type"`
+	Config map[string]string `json:"config"`
+}
+
+// Policies for Config.OnError, applied when a destination fails to write a single record, and for
+// Config.QualityPolicy, applied when a record breaks a quality rule.
+const (
+	// OnErrorFail fails the run on the first bad record; it is the default.
+	OnErrorFail = "fail"
+	// OnErrorSkip drops the record as a dead letter and carries on. Load failures skipped this
+	// way are counted in Result.Skipped.
+	OnErrorSkip = "skip"
+)
+
+// Run states reported in Result.Status.
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
 // Result captures execution state.
 type Result struct {
-	PipelineName string    `json:"pipelineName"`
-	StartedAt    time.Time `json:"startedAt"`
-	FinishedAt   time.Time `json:"finishedAt"`
-	Records      int       `json:"records"`
-	Error        string    `json:"error,omitempty"`
+	PipelineName      string              `json:"pipelineName"`
+	Status            string              `json:"status"`
+	StartedAt         time.Time           `json:"startedAt"`
+	FinishedAt        time.Time           `json:"finishedAt"`
+	Records           int                 `json:"records"`
+	DurationMs        int64               `json:"durationMs"`
+	RecordsPerSecond  float64             `json:"recordsPerSecond"`
+	DuplicatesDropped int                 `json:"duplicatesDropped,omitempty"`
+	Skipped           int                 `json:"skipped,omitempty"`
+	Quality           []RuleResult        `json:"quality,omitempty"`
+	Truncated         bool                `json:"truncated,omitempty"`
+	DryRun            bool                `json:"dryRun,omitempty"`
+	Destinations      []DestinationResult `json:"destinations,omitempty"`
+	DeadLetters       []DeadLetter        `json:"deadLetters,omitempty"`
+	RequestID         string              `json:"requestId,omitempty"`
+	Error             string              `json:"error,omitempty"`
+	// Err is the error Error describes, for matching with errors.Is. It is not serialized, so it
+	// is nil in results read back from JSON.
+	Err error `json:"-"`
+}
+
+// fail records err as the result's error; a nil err leaves the result successful.
+func (r *Result) fail(err error) {
+	r.Err = err
+	r.Error = ""
+	if err != nil {
+		r.Error = err.Error()
+	}
+}
+
+// DeadLetter is a record rejected during a run along with the reason it was rejected.
+type DeadLetter struct {
+	Record map[string]any `json:"record"`
+	Error  string         `json:"error"`
+}
+
+// DestinationResult reports the outcome of one destination in a fan-out run.
+type DestinationResult struct {
+	Type    string `json:"type"`
+	Records int    `json:"records"`
+	Skipped int    `json:"skipped,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// activeRun holds control handles for an in-flight run.
+type activeRun struct {
+	cancel context.CancelCauseFunc
+	// records counts the current attempt, which transfer increments as records reach the destinations
+	records RecordCounter
 }
 
 // Service owns registry and execution control.
 type Service struct {
 	registry *connectors.Registry
-	store    map[string]Config
-	mu       sync.RWMutex
+	store    Store
+	history  map[string][]Result
+	jobs     map[string]*job
+	active   map[string]*activeRun
+	metrics  *Metrics
+	slots    map[string]chan struct{}
+	cursors  map[string]int64
+	defaults map[connectorKey]map[string]string
+	queue    runQueue
+	breakers *circuitBreakers
+	// jobTTL and maxJobs bound how many finished async jobs are kept; guarded by mu
+	jobTTL  time.Duration
+	maxJobs int
+	// listeners and hooks are only appended to, so a copy of the slice header is safe to range over
+	// unlocked
+	listeners []ResultListener
+	hooks     []RunHook
+	mu        sync.RWMutex
+	// defs serializes read-modify-write changes to stored definitions
+	defs sync.Mutex
+
+	schedulerPaused atomic.Bool
+	strictModes     atomic.Bool
+	privateWebhooks atomic.Bool
+	// admitted counts runs holding a slot under the maxRuns ceiling, 0 meaning no ceiling
+	admitted atomic.Int64
+	maxRuns  atomic.Int64
 }
 
-// NewService builds a service with in-memory storage.
-func NewService(reg *connectors.Registry) *Service {
-	return &Service{registry: reg, store: map[string]Config{}}
+// NewService builds a service that keeps pipeline definitions in store.
+func NewService(reg *connectors.Registry, store Store) *Service {
+	return &Service{
+		registry: reg,
+		store:    store,
+		history:  map[string][]Result{},
+		jobs:     map[string]*job{},
+		active:   map[string]*activeRun{},
+		metrics:  newMetrics(),
+		slots:    map[string]chan struct{}{},
+		cursors:  map[string]int64{},
+		defaults: map[connectorKey]map[string]string{},
+		queue:    runQueue{workers: DefaultWorkers},
+		breakers: newCircuitBreakers(),
+		jobTTL:   DefaultJobTTL,
+		maxJobs:  DefaultMaxFinishedJobs,
+	}
 }
 
-// Create stores a pipeline definition.
+// Create stores a new pipeline definition, returning ErrPipelineExists if the name is taken.
 func (s *Service) Create(cfg Config) error {
-	if cfg.Name == "" {
-		return errors.New("pipeline name is required")
+	if err := s.validate(cfg); err != nil {
+		return err
+	}
+
+	s.defs.Lock()
+	defer s.defs.Unlock()
+	_, exists, err := s.store.Load(cfg.Name)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrPipelineExists
+	}
+	return s.store.Save(cfg.withDefaults())
+}
+
+// Update replaces an existing pipeline definition. An unset enabled flag keeps the current one, and
+// secret config values sent back as Redacted keep their stored values.
+func (s *Service) Update(cfg Config) error {
+	if current, ok, err := s.store.Load(cfg.Name); err == nil && ok {
+		cfg = s.unredact(cfg, current)
+	}
+	if err := s.validate(cfg); err != nil {
+		return err
+	}
+
+	s.defs.Lock()
+	defer s.defs.Unlock()
+	current, ok, err := s.store.Load(cfg.Name)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrPipelineNotFound
+	}
+	if cfg.Enabled == nil {
+		cfg.Enabled = current.Enabled
 	}
-	src, err := s.registry.SourceByName(cfg.SourceType)
+	return s.store.Save(cfg.withDefaults())
+}
+
+// CloneOptions names a copy made by Clone and overrides parts of its connector configs. Override
+// keys replace or add to the copied values; keys they leave out keep the original's.
+type CloneOptions struct {
+	Name         string
+	SourceConfig map[string]string
+	DestConfig   map[string]string
+}
+
+// Clone creates a new pipeline named opts.Name from the definition of an existing one, including its
+// stored secret values. The copy is validated like Create; a name already in use is ErrPipelineExists.
+func (s *Service) Clone(name string, opts CloneOptions) error {
+	cfg, ok, err := s.store.Load(name)
 	if err != nil {
 		return err
 	}
-	dst, err := s.registry.DestinationByName(cfg.DestType)
+	if !ok {
+		return ErrPipelineNotFound
+	}
+	cfg.Name = opts.Name
+	if len(opts.SourceConfig) > 0 {
+		cfg.SourceConfig = maps.Clone(cfg.SourceConfig)
+		if cfg.SourceConfig == nil {
+			cfg.SourceConfig = map[string]string{}
+		}
+		maps.Copy(cfg.SourceConfig, opts.SourceConfig)
+	}
+	if len(opts.DestConfig) > 0 {
+		cfg.DestConfig = maps.Clone(cfg.DestConfig)
+		if cfg.DestConfig == nil {
+			cfg.DestConfig = map[string]string{}
+		}
+		maps.Copy(cfg.DestConfig, opts.DestConfig)
+	}
+	return s.Create(cfg)
+}
+
+// SetStrictModes makes validation reject, rather than warn about, streaming sources paired with
+// batch destinations.
+func (s *Service) SetStrictModes(strict bool) {
+	s.strictModes.Store(strict)
+}
+
+// SetEnabled pauses or resumes a pipeline's schedule. Manual runs are allowed either way.
+func (s *Service) SetEnabled(name string, enabled bool) error {
+	s.defs.Lock()
+	defer s.defs.Unlock()
+	cfg, ok, err := s.store.Load(name)
 	if err != nil {
 		return err
 	}
-	if err := connectors.ValidateConnectorPair(src.Info(), dst.Info()); err != nil {
+	if !ok {
+		return ErrPipelineNotFound
+	}
+	cfg.Enabled = &enabled
+	return s.store.Save(cfg)
+}
+
+// validate resolves the connectors and checks every part of a pipeline definition, wrapping any
+// problem as a validation error.
+func (s *Service) validate(cfg Config) error {
+	if err := s.check(cfg); err != nil {
+		return validationError{err}
+	}
+	return nil
+}
+
+// check applies the validation rules to cfg.
+func (s *Service) check(cfg Config) error {
+	// required fields are checked before any connector lookup so the error names the missing field
+	switch {
+	case cfg.Name == "":
+		return errors.New("name is required")
+	case cfg.SourceType == "":
+		return errors.New("sourceType is required")
+	case cfg.DestType == "":
+		return errors.New("destType is required")
+	}
+	if cfg.MaxRetries < 0 || cfg.RetryBackoffMs < 0 || cfg.TimeoutSeconds < 0 || cfg.MaxRecords < 0 || cfg.ParallelLoaders < 0 {
+		return errors.New("maxRetries, retryBackoffMs, timeoutSeconds, maxRecords, and parallelLoaders must not be negative")
+	}
+	if cfg.Priority < 0 || cfg.Priority > MaxPriority {
+		return fmt.Errorf("priority must be between 0 and %d", MaxPriority)
+	}
+	if cfg.BufferSize < 0 || cfg.BufferSize > maxBufferSize {
+		return fmt.Errorf("bufferSize must be between 0 and %d", maxBufferSize)
+	}
+	if err := checkConfigMap("sourceConfig", cfg.SourceConfig); err != nil {
+		return err
+	}
+	if err := checkConfigMap("destConfig", cfg.DestConfig); err != nil {
 		return err
 	}
-	if err := src.Validate(cfg.SourceConfig); err != nil {
+	for i, o := range cfg.Sources {
+		if err := checkConfigMap(fmt.Sprintf("sources[%d].config", i), o.Config); err != nil {
+			return err
+		}
+	}
+	for i, d := range cfg.Destinations {
+		if err := checkConfigMap(fmt.Sprintf("destinations[%d].config", i), d.Config); err != nil {
+			return err
+		}
+	}
+	if err := s.checkRedacted(cfg); err != nil {
 		return err
 	}
-	if err := dst.Validate(cfg.DestConfig); err != nil {
+	if cfg.OnError != "" && cfg.OnError != OnErrorFail && cfg.OnError != OnErrorSkip {
+		return fmt.Errorf("onError must be %s or %s", OnErrorFail, OnErrorSkip)
+	}
+	if cfg.QualityPolicy != "" && cfg.QualityPolicy != OnErrorFail && cfg.QualityPolicy != OnErrorSkip {
+		return fmt.Errorf("qualityPolicy must be %s or %s", OnErrorFail, OnErrorSkip)
+	}
+	if cfg.Schedule != "" {
+		if _, err := parseCron(cfg.Schedule); err != nil {
+			return err
+		}
+	}
+	if cfg.WebhookURL != "" {
+		if u, err := url.Parse(cfg.WebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.New("webhookUrl must be an absolute http or https URL")
+		} else if !s.privateWebhooks.Load() {
+			if err := checkWebhookHost(u.Hostname()); err != nil {
+				return err
+			}
+		}
+	}
+	p, err := s.resolve(cfg)
+	if err != nil {
+		return err
+	}
+	for _, o := range p.sources {
+		for _, t := range p.targets {
+			if err := connectors.ValidateConnectorPair(o.src.Info(), t.dst.Info(), s.strictModes.Load()); err != nil {
+				return p.pairError(o, t, err)
+			}
+		}
+	}
+	for _, o := range p.sources {
+		if err := o.src.Validate(o.config); err != nil {
+			return p.sourceError(o, err)
+		}
+	}
+	for _, t := range p.targets {
+		if err := t.dst.Validate(t.config); err != nil {
+			return p.targetError(t, err)
+		}
+	}
+	for _, o := range p.sources {
+		for _, t := range p.targets {
+			if err := connectors.ValidateNotSelfLoop(o.src.Info(), t.dst.Info(), o.config, t.config); err != nil {
+				return p.pairError(o, t, err)
+			}
+		}
+	}
+	if cfg.CheckSchema {
+		return s.checkSchema(p)
+	}
+	return nil
+}
+
+// checkConfigMap bounds the size of a connector config and requires identifier-like keys
+// (a letter or underscore followed by letters, digits, underscores, dots, or dashes).
+func checkConfigMap(label string, config map[string]string) error {
+	if len(config) > maxConfigEntries {
+		return fmt.Errorf("%s has %d entries, more than the limit of %d", label, len(config), maxConfigEntries)
+	}
+	for _, key := range slices.Sorted(maps.Keys(config)) {
+		if !isConfigKey(key) {
+			return fmt.Errorf("%s key %q is not a valid identifier", label, key)
+		}
+		if len(config[key]) > maxConfigValueLength {
+			return fmt.Errorf("%s value for %s is longer than %d bytes", label, key, maxConfigValueLength)
+		}
+	}
+	return nil
+}
+
+// configLabel names the i-th connector config of resolve's combined list, in which the primary
+// connector comes first and the entries of the named list follow.
+func configLabel(primary, list string, i int) string {
+	if i == 0 {
+		return primary
+	}
+	return fmt.Sprintf("%s[%d].config", list, i-1)
+}
+
+// secretEnvPrefix starts the names of the environment variables connector configs may reference.
+// Anything else in the server's environment, such as its own keys, stays out of reach.
+const secretEnvPrefix = "PIPELINE_SECRET_"
+
+// expandEnv returns a copy of config with every ${NAME} replaced by the value of that environment
+// variable, so secrets can stay out of stored definitions. Only names starting with
+// secretEnvPrefix may be referenced. Variables are read at each resolve, and an unset or disallowed
+// variable is an error naming it.
+func expandEnv(label string, config map[string]string) (map[string]string, error) {
+	if config == nil {
+		return nil, nil
+	}
+	expanded := make(map[string]string, len(config))
+	for key, value := range config {
+		var b strings.Builder
+		rest := value
+		for {
+			before, after, found := strings.Cut(rest, "${")
+			b.WriteString(before)
+			if !found {
+				break
+			}
+			name, tail, closed := strings.Cut(after, "}")
+			if !closed || !isEnvName(name) {
+				return nil, fmt.Errorf("%s %s: ${ must be followed by a variable name and }", label, key)
+			}
+			if !strings.HasPrefix(name, secretEnvPrefix) {
+				return nil, fmt.Errorf("%s %s: environment variable %s may not be referenced, only names starting with %s", label, key, name, secretEnvPrefix)
+			}
+			v, ok := os.LookupEnv(name)
+			if !ok {
+				return nil, fmt.Errorf("%s %s: environment variable %s is not set", label, key, name)
+			}
+			b.WriteString(v)
+			rest = tail
+		}
+		expanded[key] = b.String()
+	}
+	return expanded, nil
+}
+
+// isEnvName reports whether name is a shell-style variable name: letters, digits, and
+// underscores, not starting with a digit.
+func isEnvName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, c := range name {
+		if c != '_' && !('a' <= c && c <= 'z') && !('A' <= c && c <= 'Z') && (i == 0 || c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+func isConfigKey(key string) bool {
+	if key == "" || len(key) > maxConfigKeyLength {
+		return false
+	}
+	for i, c := range key {
+		switch {
+		case c == '_', 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z':
+		case i > 0 && ('0' <= c && c <= '9' || c == '.' || c == '-'):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// checkSchema samples every source, passes the sample through the plan's transforms, and checks
+// that each destination can store the inferred field types.
+func (s *Service) checkSchema(p plan) error {
+	ctx, cancel := context.WithTimeout(context.Background(), schemaCheckTimeout)
+	defer cancel()
+	for _, o := range p.sources {
+		sample, err := s.registry.Sample(ctx, o.src.Info().Name, o.config, schemaSampleSize)
+		if err != nil {
+			return p.sourceError(o, fmt.Errorf("sample for schema check: %w", err))
+		}
+		transformed := sample[:0]
+		for _, record := range sample {
+			var err error
+			for _, t := range p.transforms {
+				if record, err = t.Apply(record); err != nil {
+					break
+				}
+			}
+			// records a transform rejects never reach the destination
+			if err == nil {
+				transformed = append(transformed, record)
+			}
+		}
+		fields := connectors.InferSchema(transformed)
+		for _, t := range p.targets {
+			if err := connectors.CheckSchema(t.dst.Info(), fields); err != nil {
+				return p.pairError(o, t, err)
+			}
+		}
+	}
+	return nil
+}
+
+// plan holds the connectors and stages a pipeline definition resolves to.
+type plan struct {
+	sources    []origin
+	targets    []target
+	transforms []namedTransform
+	dedupeKey  string
+	maxRecords int
+	skipFailed bool
+	rules      []*qualityRule
+	// skipViolations routes records that break a rule to dead letters instead of failing the run
+	skipViolations bool
+	// startOffset is passed to every source when resume is set
+	startOffset int64
+	resume      bool
+	bufferSize  int
+	breakers    *circuitBreakers
+}
+
+// origin is one source a run extracts from.
+type origin struct {
+	src    connectors.Source
+	config map[string]string
+}
+
+// target is one destination a run loads into.
+type target struct {
+	dst    connectors.Destination
+	config map[string]string
+	// loaders is the number of concurrent Load calls draining the target's records
+	loaders int
+}
+
+// resolve looks up the connectors and transforms named by cfg.
+func (s *Service) resolve(cfg Config) (plan, error) {
+	p := plan{dedupeKey: cfg.DedupeKey, bufferSize: cfg.BufferSize, maxRecords: cfg.MaxRecords, skipFailed: cfg.OnError == OnErrorSkip, skipViolations: cfg.QualityPolicy == OnErrorSkip, breakers: s.breakers}
+	sources := append([]SourceConfig{{Type: cfg.SourceType, Config: cfg.SourceConfig}}, cfg.Sources...)
+	for i, o := range sources {
+		src, err := s.registry.SourceByName(o.Type)
+		if err != nil {
+			return plan{}, err
+		}
+		config, err := expandEnv(configLabel("sourceConfig", "sources", i), s.withConnectorDefaults(connectors.SourceType, src.Info().Name, o.Config))
+		if err != nil {
+			return plan{}, err
+		}
+		p.sources = append(p.sources, origin{src: src, config: config})
+	}
+	dests := append([]DestConfig{{Type: cfg.DestType, Config: cfg.DestConfig}}, cfg.Destinations...)
+	for i, d := range dests {
+		dst, err := s.registry.DestinationByName(d.Type)
+		if err != nil {
+			return plan{}, err
+		}
+		config, err := expandEnv(configLabel("destConfig", "destinations", i), s.withConnectorDefaults(connectors.DestinationType, dst.Info().Name, d.Config))
+		if err != nil {
+			return plan{}, err
+		}
+		// parallelLoaders is capped at what the destination advertises
+		loaders := max(min(cfg.ParallelLoaders, dst.Info().MaxParallel), 1)
+		p.targets = append(p.targets, target{dst: dst, config: config, loaders: loaders})
+	}
+	var err error
+	if p.transforms, err = resolveTransforms(cfg.Transforms, cfg.TransformConfig); err != nil {
+		return plan{}, err
+	}
+	if len(cfg.Mapping) > 0 || cfg.DropUnmapped {
+		m, err := newFieldMapping(cfg.Mapping, cfg.DropUnmapped)
+		if err != nil {
+			return plan{}, err
+		}
+		// renames run first so transforms see destination field names
+		p.transforms = append([]namedTransform{{name: "mapping", Transform: m}}, p.transforms...)
+	}
+	if p.rules, err = compileRules(cfg.QualityRules); err != nil {
+		return plan{}, err
+	}
+	return p, nil
+}
+
+// extractConfig returns the config a source extracts with, adding the run's cursor and buffer size.
+func (p plan) extractConfig(o origin) map[string]string {
+	if !p.resume && p.bufferSize == 0 {
+		return o.config
+	}
+	config := maps.Clone(o.config)
+	if config == nil {
+		config = map[string]string{}
+	}
+	if p.resume {
+		config[connectors.StartOffsetKey] = strconv.FormatInt(p.startOffset, 10)
+	}
+	if p.bufferSize > 0 {
+		config[connectors.BufferSizeKey] = strconv.Itoa(p.bufferSize)
+	}
+	return config
+}
+
+// sourceError names the source an error belongs to when a plan merges several.
+func (p plan) sourceError(o origin, err error) error {
+	if len(p.sources) == 1 {
+		return err
+	}
+	return fmt.Errorf("source %s: %w", o.src.Info().Name, err)
+}
+
+// pairError names the source and destination an error belongs to, as far as the plan is ambiguous.
+func (p plan) pairError(o origin, t target, err error) error {
+	return p.sourceError(o, p.targetError(t, err))
+}
+
+// targetError names the destination an error belongs to when a plan fans out to several.
+func (p plan) targetError(t target, err error) error {
+	if len(p.targets) == 1 {
+		return err
+	}
+	return fmt.Errorf("destination %s: %w", t.dst.Info().Name, err)
+}
+
+// Delete removes a pipeline definition.
+func (s *Service) Delete(name string) error {
+	s.defs.Lock()
+	defer s.defs.Unlock()
+	_, ok, err := s.store.Load(name)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrPipelineNotFound
+	}
+	if err := s.store.Delete(name); err != nil {
 		return err
 	}
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.store[cfg.Name] = cfg
+	delete(s.history, name)
+	delete(s.cursors, name)
 	return nil
 }
 
-// List returns all pipeline configs.
-func (s *Service) List() []Config {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+// Get returns a single pipeline config by name, with secret config values redacted.
+func (s *Service) Get(name string) (Config, bool) {
+	cfg, ok := s.getConfig(name)
+	if !ok {
+		return Config{}, false
+	}
+	return s.redact(cfg), true
+}
 
-	var result []Config
-	for _, cfg := range s.store {
-		result = append(result, cfg)
+// ListOptions narrows and pages the pipeline list. Zero values mean no filter and no limit.
+type ListOptions struct {
+	SourceType string
+	Limit      int
+	Offset     int
+}
+
+// List returns pipeline configs sorted by name, filtered and paged by opts,
+// along with the number of configs that matched before paging. Secret config values are redacted.
+func (s *Service) List(opts ListOptions) ([]Config, int, error) {
+	all, err := s.store.All()
+	if err != nil {
+		return nil, 0, err
 	}
-	return result
+	matched := all[:0:0]
+	for _, cfg := range all {
+		if opts.SourceType != "" && cfg.SourceType != opts.SourceType {
+			continue
+		}
+		matched = append(matched, s.redact(cfg.withDefaults()))
+	}
+
+	total := len(matched)
+	start := min(opts.Offset, total)
+	end := total
+	if opts.Limit > 0 {
+		end = min(start+opts.Limit, total)
+	}
+	return matched[start:end], total, nil
+}
+
+// Export returns every pipeline definition as stored, secrets included, for backups that must
+// restore onto a server that has never seen them. Callers must keep it from anyone List's redaction
+// is meant for.
+func (s *Service) Export() ([]Config, error) {
+	all, err := s.store.All()
+	if err != nil {
+		return nil, err
+	}
+	for i, cfg := range all {
+		all[i] = cfg.withDefaults()
+	}
+	return all, nil
 }
 
 // Run triggers extraction and load for a pipeline.
 func (s *Service) Run(ctx context.Context, name string) Result {
-	s.mu.RLock()
-	cfg, ok := s.store[name]
-	s.mu.RUnlock()
+	return s.RunWithProgress(ctx, name, nil)
+}
 
-	res := Result{
+// RunWithProgress behaves like Run and additionally reports the running record count of the
+// current attempt to progress, which is called from the pipeline goroutines and must be cheap.
+func (s *Service) RunWithProgress(ctx context.Context, name string, progress func(records int)) Result {
+	if !s.admitRun() {
+		// like a rejected concurrent run, a shed run is not recorded
+		res := Result{PipelineName: name, StartedAt: time.Now(), RequestID: RequestID(ctx)}
+		res.fail(ErrServerBusy)
+		return settle(res)
+	}
+	defer s.releaseRun()
+	return s.run(ctx, name, progress)
+}
+
+// run executes a pipeline whose run slot has already been taken. A panic during the run is recorded
+// as a failed Result instead of crashing the process, which matters most for queued runs, whose
+// worker goroutines have nothing above them to recover.
+func (s *Service) run(ctx context.Context, name string, progress func(records int)) (res Result) {
+	res = Result{
 		PipelineName: name,
+		Status:       StatusRunning,
 		StartedAt:    time.Now(),
+		RequestID:    RequestID(ctx),
 	}
+	defer func() {
+		if p := recover(); p != nil {
+			res.fail(recovered(ctx, "pipeline "+name+" run", p))
+			res = s.finish(ctx, res)
+		}
+	}()
+
+	// snapshot the config so a concurrent Delete cannot affect an in-flight run
+	cfg, ok := s.getConfig(name)
 
 	if !ok {
-		res.Error = "pipeline not found"
-		res.FinishedAt = time.Now()
-		return res
+		res.fail(ErrPipelineNotFound)
+		return s.finish(ctx, res)
 	}
 
-	src, err := s.registry.SourceByName(cfg.SourceType)
-	if err != nil {
-		res.Error = err.Error()
-		res.FinishedAt = time.Now()
-		return res
+	if cfg.TimeoutSeconds > 0 {
+		timeout := time.Duration(cfg.TimeoutSeconds) * time.Second
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeoutCause(ctx, timeout, fmt.Errorf("run exceeded its %s deadline", timeout))
+		defer cancelTimeout()
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	run, ok := s.track(name, cancel)
+	if !ok {
+		// the rejected attempt is not recorded so it cannot be mistaken for the in-flight run
+		res.fail(ErrAlreadyRunning)
+		return settle(res)
+	}
+	defer s.untrack(name, run)
+
+	slog.InfoContext(ctx, "pipeline run started", "pipeline", name, "source", cfg.SourceType, "destination", cfg.DestType)
+
+	cfg = cfg.clone()
+	if err := s.beforeRun(ctx, &cfg); err != nil {
+		res.fail(err)
+		return s.finish(ctx, res)
 	}
-	dst, err := s.registry.DestinationByName(cfg.DestType)
+	cfg.Name = name
+	// hooks may rewrite the definition, so it is checked again like a submitted one; the schema
+	// check is left to creation time, since it samples the sources
+	checked := cfg
+	checked.CheckSchema = false
+	if err := s.validate(checked); err != nil {
+		res.fail(err)
+		return s.finish(ctx, res)
+	}
+
+	p, err := s.resolve(cfg)
 	if err != nil {
-		res.Error = err.Error()
-		res.FinishedAt = time.Now()
-		return res
+		res.fail(err)
+		return s.finish(ctx, res)
+	}
+	if cursor, ok := s.Cursor(name); ok {
+		p.startOffset, p.resume = cursor+1, true
 	}
 
-	records, err := src.Extract(ctx, cfg.SourceConfig)
+	release, err := s.acquire(ctx, p)
 	if err != nil {
-		res.Error = err.Error()
-		res.FinishedAt = time.Now()
-		return res
+		res.fail(failure(ctx, err))
+		return s.finish(ctx, res)
 	}
+	defer release()
 
-	// fan-out to count processed rows while loading
-	counter := 0
-	loadErr := dst.Load(ctx, cfg.DestConfig, Tee(records, func(m map[string]any) {
-		counter++
+	var (
+		last     attempt
+		runErr   error
+		attempts int
+	)
+	for {
+		attempts++
+		probes, err := s.breakers.allow(p.targets)
+		if runErr = err; runErr != nil {
+			break
+		}
+		func() {
+			defer s.breakers.endProbes(probes)
+			last, runErr = transfer(ctx, p, cancel, &run.records, progress)
+		}()
+		if runErr == nil || attempts > cfg.MaxRetries || ctx.Err() != nil {
+			break
+		}
+		backoff := retryBackoff(cfg.RetryBackoffMs, attempts)
+		slog.WarnContext(ctx, "pipeline attempt failed, retrying",
+			"pipeline", name, "attempt", attempts, "backoffMs", backoff.Milliseconds(), "error", runErr)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+
+	// a cancelled source may close its channel cleanly, so the cause is checked even without a load error
+	runErr = failure(ctx, runErr)
+	if runErr != nil && attempts > 1 {
+		runErr = fmt.Errorf("%w (after %d attempts)", runErr, attempts)
+	}
+	res.fail(runErr)
+	if res.Error == "" && last.advanced {
+		s.setCursor(name, last.cursor)
+	}
+	res.Records = last.records
+	res.Destinations = last.destinations
+	res.DuplicatesDropped = last.duplicates
+	res.Skipped = last.skipped
+	res.Quality = last.quality
+	res.DeadLetters = last.deadLetters
+	res.Truncated = last.truncated
+	return s.finish(ctx, res)
+}
+
+// failure returns a run's error, preferring the cancellation cause (user cancel, timeout, transform
+// failure) over the raw context error.
+func failure(ctx context.Context, err error) error {
+	if cause := context.Cause(ctx); cause != nil && !errors.Is(cause, context.Canceled) {
+		return cause
+	}
+	return err
+}
+
+// attempt summarises a single extract and load attempt.
+type attempt struct {
+	records      int
+	destinations []DestinationResult
+	duplicates   int
+	skipped      int
+	quality      []RuleResult
+	deadLetters  []DeadLetter
+	// cursor is the highest record offset extracted, if advanced
+	cursor    int64
+	advanced  bool
+	truncated bool
+}
+
+// transfer performs a single extract and load attempt, reporting the number of records loaded
+// and, for fan-out plans, the per-destination breakdown. counter is reset and then counts the
+// records handed to the destinations; progress, if set, is called with each new count.
+func transfer(ctx context.Context, p plan, fail context.CancelCauseFunc, counter *RecordCounter, progress func(int)) (attempt, error) {
+	// scope producers to the attempt so an early return never strands an extract goroutine
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// extraction alone is stopped once maxRecords is reached, letting the loads finish normally
+	extractCtx, stopExtract := context.WithCancel(ctx)
+	defer stopExtract()
+
+	streams := make([]<-chan map[string]any, 0, len(p.sources))
+	for _, o := range p.sources {
+		// a source failing mid-stream fails the run, instead of looking like it reached its end
+		srcCtx := connectors.WithExtractErrorHandler(extractCtx, func(err error) { fail(p.sourceError(o, err)) })
+		var records <-chan map[string]any
+		err := safely(ctx, "source "+o.src.Info().Name+" extract", func() (err error) {
+			records, err = o.src.Extract(srcCtx, p.extractConfig(o))
+			return err
+		})
+		if err != nil {
+			return attempt{}, p.sourceError(o, err)
+		}
+		streams = append(streams, records)
+	}
+	records := streams[0]
+	if len(streams) > 1 {
+		records = Merge(extractCtx, streams...)
+	}
+	// every stage closes its output as its goroutine exits; see the end of the attempt
+	var stages []<-chan map[string]any
+	stage := func(out <-chan map[string]any) <-chan map[string]any {
+		stages = append(stages, out)
+		return out
+	}
+	var truncated atomic.Bool
+	if p.maxRecords > 0 {
+		records = stage(limitRecords(ctx, records, p.maxRecords, stopExtract, &truncated))
+	}
+	// the cursor follows extraction so records dropped by later stages are not re-read next run
+	var (
+		cursor   int64
+		advanced bool
+	)
+	records = stage(tee(ctx, records, p.bufferSize, func(m map[string]any) {
+		if offset, ok := m["offset"].(int64); ok && (!advanced || offset > cursor) {
+			cursor, advanced = offset, true
+		}
 	}))
+	var rejected deadLetters
+	if len(p.transforms) > 0 {
+		records = stage(transformRecords(ctx, records, p.transforms, fail, &rejected))
+	}
+	var duplicates atomic.Int64
+	if p.dedupeKey != "" {
+		records = stage(dedupe(ctx, records, p.dedupeKey, &duplicates))
+	}
+	counts := make([]ruleCount, len(p.rules))
+	if len(p.rules) > 0 {
+		records = stage(checkQuality(ctx, records, p.rules, counts, !p.skipViolations, fail, &rejected))
+	}
+
+	// count the records the loaders take while loading
+	counter.Reset()
+	records, counted := countRecords(ctx, records, counter, progress)
+	var (
+		results []DestinationResult
+		skipped int
+		err     error
+	)
+	if len(p.targets) == 1 {
+		var failed atomic.Int64
+		err = p.load(p.loadContext(ctx, &failed, &rejected), p.targets[0], records)
+		// a single destination reports only the records it wrote
+		skipped = int(failed.Load())
+		counter.Add(-int64(skipped))
+	} else {
+		results, err = p.loadFanout(ctx, records, &rejected)
+		for _, r := range results {
+			skipped += r.Skipped
+		}
+	}
+
+	// After a failed load the stages may still be running. Cancelling stops them, and draining
+	// every stage output until it closes waits for all of them to exit, so none is left blocked on
+	// a send and none still writes the cursor or the tallies read below. The counting stage is
+	// waited on rather than drained, as a record it handed to the drain would be counted. After a
+	// successful load the outputs are already closed.
+	cancel()
+	for _, out := range stages {
+		for range out {
+		}
+	}
+	<-counted
+	return attempt{
+		records:      int(counter.Count()),
+		destinations: results,
+		duplicates:   int(duplicates.Load()),
+		skipped:      skipped,
+		quality:      ruleResults(p.rules, counts),
+		deadLetters:  rejected.list(),
+		cursor:       cursor,
+		advanced:     advanced,
+		truncated:    truncated.Load(),
+	}, err
+}
+
+// limitRecords forwards the first n records and then closes its output. When in has more, it sets
+// truncated, calls stop to cancel extraction, and drains in so no producer is left blocked. A
+// cancelled ctx ends forwarding the same way.
+func limitRecords(ctx context.Context, in <-chan map[string]any, n int, stop context.CancelFunc, truncated *atomic.Bool) <-chan map[string]any {
+	out := make(chan map[string]any)
+	go func() {
+		forwarded := 0
+	forward:
+		for record := range in {
+			if forwarded == n {
+				truncated.Store(true)
+				break
+			}
+			select {
+			case <-ctx.Done():
+				break forward
+			case out <- record:
+			}
+			forwarded++
+		}
+		close(out)
+		stop()
+		for range in {
+		}
+	}()
+	return out
+}
+
+// countRecords forwards records unbuffered and counts each one only after a loader has taken it,
+// so a failed load reports the records its destination received and not one more. progress, if
+// set, is called with each new count. It closes done once its goroutine has exited.
+func countRecords(ctx context.Context, in <-chan map[string]any, counter *RecordCounter, progress func(int)) (_ <-chan map[string]any, done <-chan struct{}) {
+	out := make(chan map[string]any)
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		defer close(out)
+		for record := range in {
+			select {
+			case <-ctx.Done():
+				return
+			case out <- record:
+			}
+			n := counter.Add(1)
+			if progress != nil {
+				progress(int(n))
+			}
+		}
+	}()
+	return out, exited
+}
+
+// dedupe drops records whose key field repeats a value already seen in the run, counting them in
+// dropped. Records without the key pass through. Every distinct key is held in memory until the
+// attempt ends, so very large runs should dedupe downstream instead.
+func dedupe(ctx context.Context, in <-chan map[string]any, key string, dropped *atomic.Int64) <-chan map[string]any {
+	out := make(chan map[string]any)
+	go func() {
+		defer close(out)
+		seen := map[string]struct{}{}
+		for record := range in {
+			if v, ok := record[key]; ok {
+				// the type is part of the key so 1 and "1" stay distinct
+				k := fmt.Sprintf("%T:%v", v, v)
+				if _, dup := seen[k]; dup {
+					dropped.Add(1)
+					continue
+				}
+				seen[k] = struct{}{}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case out <- record:
+			}
+		}
+	}()
+	return out
+}
+
+// loadContext returns the context a load runs with. Under onError skip, records the destination
+// fails to write are counted in skipped and kept as dead letters instead of failing the load.
+func (p plan) loadContext(ctx context.Context, skipped *atomic.Int64, rejected *deadLetters) context.Context {
+	if !p.skipFailed {
+		return ctx
+	}
+	return connectors.WithRecordErrorHandler(ctx, func(record map[string]any, err error) error {
+		skipped.Add(1)
+		rejected.add(record, fmt.Errorf("load: %w", err))
+		return nil
+	})
+}
+
+// loadFanout loads a copy of every record into each target concurrently. A target that fails
+// keeps being drained so the remaining targets still complete.
+func (p plan) loadFanout(ctx context.Context, in <-chan map[string]any, rejected *deadLetters) ([]DestinationResult, error) {
+	branches := Fanout(ctx, in, len(p.targets))
+	results := make([]DestinationResult, len(p.targets))
+	var wg sync.WaitGroup
+	for i, t := range p.targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var (
+				count   RecordCounter
+				skipped atomic.Int64
+			)
+			records := Tee(ctx, branches[i], count.Observe)
+			err := p.load(p.loadContext(ctx, &skipped, rejected), t, records)
+			results[i] = DestinationResult{
+				Type:    t.dst.Info().Name,
+				Records: int(count.Count() - skipped.Load()),
+				Skipped: int(skipped.Load()),
+			}
+			if err != nil {
+				results[i].Error = err.Error()
+			}
+			for range records {
+			}
+		}()
+	}
+	wg.Wait()
+
+	var failed []string
+	for _, r := range results {
+		if r.Error != "" {
+			failed = append(failed, fmt.Sprintf("destination %s: %s", r.Type, r.Error))
+		}
+	}
+	if len(failed) > 0 {
+		return results, errors.New(strings.Join(failed, "; "))
+	}
+	return results, nil
+}
+
+// load loads records into t and records the outcome with the destination's circuit breaker.
+func (p plan) load(ctx context.Context, t target, records <-chan map[string]any) error {
+	err := t.load(ctx, records)
+	// a load cut short by cancellation says nothing about the destination
+	if ctx.Err() == nil {
+		p.breakers.record(t.dst.Info().Name, err)
+	}
+	return err
+}
+
+// load runs the target's loaders against the shared records channel. The first loader to fail
+// stops the others and its error is returned, as the load has failed either way. Records left in
+// the channel after a failure are for the caller to drain.
+func (t target) load(ctx context.Context, records <-chan map[string]any) error {
+	what := "destination " + t.dst.Info().Name + " load"
+	if t.loaders <= 1 {
+		return safely(ctx, what, func() error { return t.dst.Load(ctx, t.config, records) })
+	}
+	loadCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	errs := make([]error, t.loaders)
+	var wg sync.WaitGroup
+	for i := range t.loaders {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if errs[i] = safely(ctx, what, func() error { return t.dst.Load(loadCtx, t.config, records) }); errs[i] != nil {
+				cancel(errs[i])
+			}
+		}()
+	}
+	wg.Wait()
+	if ctx.Err() == nil && loadCtx.Err() != nil {
+		return context.Cause(loadCtx)
+	}
+	return errors.Join(errs...)
+}
 
-	if loadErr != nil {
-		res.Error = loadErr.Error()
+// retryBackoff doubles the base delay for every failed attempt, capped at maxRetryBackoff.
+func retryBackoff(baseMs, attempt int) time.Duration {
+	backoff := time.Duration(baseMs) * time.Millisecond
+	for i := 1; i < attempt && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxRetryBackoff)
+}
+
+// DryRun validates a stored pipeline end to end without extracting or loading records.
+// Dry runs are not recorded in the run history or metrics.
+func (s *Service) DryRun(ctx context.Context, name string) Result {
+	res := Result{
+		PipelineName: name,
+		Status:       StatusRunning,
+		StartedAt:    time.Now(),
+		DryRun:       true,
+		RequestID:    RequestID(ctx),
+	}
+	cfg, ok := s.getConfig(name)
+	if !ok {
+		res.fail(ErrPipelineNotFound)
+	} else if err := s.validate(cfg); err != nil {
+		res.fail(err)
 	}
-	res.Records = counter
+	return settle(res)
+}
+
+// Cancel stops the in-flight run of a pipeline. It returns ErrNotRunning when the pipeline has no
+// run in flight and ErrPipelineNotFound when it does not exist.
+func (s *Service) Cancel(name string) error {
+	s.mu.RLock()
+	run, ok := s.active[name]
+	s.mu.RUnlock()
+	if ok {
+		run.cancel(errCancelledByUser)
+		return nil
+	}
+	_, exists, err := s.store.Load(name)
+	switch {
+	case err != nil:
+		return err
+	case !exists:
+		return fmt.Errorf("%w: %s", ErrPipelineNotFound, name)
+	}
+	return fmt.Errorf("%w: %s", ErrNotRunning, name)
+}
+
+// acquire takes a concurrency slot for every connector in the plan, waiting while a connector is
+// already serving MaxParallel runs. Slots are taken in key order so runs cannot deadlock each other.
+func (s *Service) acquire(ctx context.Context, p plan) (release func(), err error) {
+	limits := map[string]int{}
+	for _, o := range p.sources {
+		info := o.src.Info()
+		limits[string(info.Type)+":"+info.Name] = info.MaxParallel
+	}
+	for _, t := range p.targets {
+		info := t.dst.Info()
+		limits[string(info.Type)+":"+info.Name] = info.MaxParallel
+	}
+	keys := slices.Sorted(maps.Keys(limits))
+
+	var held []chan struct{}
+	release = func() {
+		for _, slot := range held {
+			<-slot
+		}
+	}
+	for _, key := range keys {
+		slot := s.slot(key, limits[key])
+		select {
+		case slot <- struct{}{}:
+			held = append(held, slot)
+		default:
+			slog.InfoContext(ctx, "waiting for connector slot", "connector", key, "maxParallel", cap(slot))
+			select {
+			case slot <- struct{}{}:
+				held = append(held, slot)
+			case <-ctx.Done():
+				release()
+				return nil, fmt.Errorf("waiting for %s connector slot: %w", key, ctx.Err())
+			}
+		}
+	}
+	return release, nil
+}
+
+// slot returns the semaphore for a connector, creating it with the advertised capacity on first use.
+func (s *Service) slot(key string, maxParallel int) chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	slot, ok := s.slots[key]
+	if !ok {
+		slot = make(chan struct{}, max(maxParallel, 1))
+		s.slots[key] = slot
+	}
+	return slot
+}
+
+// Cursor returns the highest record offset loaded by the pipeline's last successful run, if any.
+func (s *Service) Cursor(name string) (int64, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	cursor, ok := s.cursors[name]
+	return cursor, ok
+}
+
+// ResetCursor forgets the pipeline's cursor so the next run extracts from the beginning.
+func (s *Service) ResetCursor(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.cursors, name)
+}
+
+func (s *Service) setCursor(name string, cursor int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cursors[name] = cursor
+}
+
+// ActiveRuns reports how many runs are currently in flight.
+func (s *Service) ActiveRuns() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.active)
+}
+
+// Shutdown fails queued runs and waits for in-flight runs to finish. When ctx expires first, the
+// remaining runs are cancelled and Shutdown waits for them to wind down before returning ctx's error.
+func (s *Service) Shutdown(ctx context.Context) error {
+	s.closeQueue()
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	for s.ActiveRuns() > 0 {
+		select {
+		case <-ctx.Done():
+			s.mu.RLock()
+			for _, run := range s.active {
+				run.cancel(errShuttingDown)
+			}
+			s.mu.RUnlock()
+			for s.ActiveRuns() > 0 {
+				<-ticker.C
+			}
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// Progress returns the record count of the pipeline's in-flight attempt. When nothing is running it
+// returns the count of the most recent run, if any.
+func (s *Service) Progress(name string) (records int64, running bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if run, ok := s.active[name]; ok {
+		return run.records.Count(), true
+	}
+	if runs := s.history[name]; len(runs) > 0 {
+		return int64(runs[len(runs)-1].Records), false
+	}
+	return 0, false
+}
+
+// IsRunning reports whether the pipeline has a run in flight.
+func (s *Service) IsRunning(name string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.active[name]
+	return ok
+}
+
+// track registers a starting run, refusing when the pipeline already has one in flight.
+func (s *Service) track(name string, cancel context.CancelCauseFunc) (*activeRun, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.active[name]; ok {
+		return nil, false
+	}
+	run := &activeRun{cancel: cancel}
+	s.active[name] = run
+	return run, true
+}
+
+// untrack removes a finished run.
+func (s *Service) untrack(name string, run *activeRun) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.active[name] == run {
+		delete(s.active, name)
+	}
+}
+
+// Metrics exposes the execution counters.
+func (s *Service) Metrics() *Metrics {
+	return s.metrics
+}
+
+// Stats aggregates the retained run history of every pipeline.
+type Stats struct {
+	Pipelines     int     `json:"pipelines"`
+	Runs          int     `json:"runs"`
+	Records       int     `json:"records"`
+	Succeeded     int     `json:"succeeded"`
+	Failed        int     `json:"failed"`
+	AvgDurationMs float64 `json:"avgDurationMs"`
+}
+
+// Stats counts the stored pipelines and totals their retained runs. Only the bounded history is
+// considered, so older runs no longer count.
+func (s *Service) Stats() (Stats, error) {
+	configs, err := s.store.All()
+	if err != nil {
+		return Stats{}, err
+	}
+	stats := Stats{Pipelines: len(configs)}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var totalMs int64
+	for _, runs := range s.history {
+		for _, res := range runs {
+			stats.Runs++
+			stats.Records += res.Records
+			totalMs += res.DurationMs
+			switch res.Status {
+			case StatusSucceeded:
+				stats.Succeeded++
+			case StatusFailed:
+				stats.Failed++
+			}
+		}
+	}
+	if stats.Runs > 0 {
+		stats.AvgDurationMs = float64(totalMs) / float64(stats.Runs)
+	}
+	return stats, nil
+}
+
+// HistoryOptions narrows the run history. Zero values mean no filter.
+type HistoryOptions struct {
+	// Since keeps runs started at or after it.
+	Since  time.Time
+	Status string
+}
+
+// History returns the retained results for a pipeline that match opts, newest first.
+func (s *Service) History(name string, opts HistoryOptions) []Result {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	runs := s.history[name]
+	result := make([]Result, 0, len(runs))
+	for i := len(runs) - 1; i >= 0; i-- {
+		if runs[i].StartedAt.Before(opts.Since) || (opts.Status != "" && runs[i].Status != opts.Status) {
+			continue
+		}
+		result = append(result, runs[i])
+	}
+	return result
+}
+
+// finish stamps the completion time, records the result in the pipeline history, sends the
+// pipeline's webhook notification, and passes the result to the result listeners.
+func (s *Service) finish(ctx context.Context, res Result) Result {
+	res = settle(res)
+	slog.InfoContext(ctx, "pipeline run finished",
+		"pipeline", res.PipelineName,
+		"status", res.Status,
+		"records", res.Records,
+		"durationMs", res.DurationMs,
+		"error", res.Error,
+	)
+
+	defer s.notifyListeners(ctx, res)
+
+	cfg, ok := s.getConfig(res.PipelineName)
+	if !ok {
+		return res
+	}
+	if cfg.WebhookURL != "" {
+		notify(cfg.WebhookURL, res, s.privateWebhooks.Load())
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.metrics.observe(res)
+	runs := append(s.history[res.PipelineName], res)
+	if len(runs) > maxHistory {
+		runs = runs[len(runs)-maxHistory:]
+	}
+	s.history[res.PipelineName] = runs
+	return res
+}
+
+// settle stamps the completion time and derives duration, throughput, and status.
+func settle(res Result) Result {
 	res.FinishedAt = time.Now()
+	elapsed := res.FinishedAt.Sub(res.StartedAt)
+	res.DurationMs = elapsed.Milliseconds()
+	// sub-millisecond runs report no rate rather than an inflated or infinite one
+	if elapsed >= time.Millisecond {
+		res.RecordsPerSecond = float64(res.Records) / elapsed.Seconds()
+	}
+	res.Status = StatusSucceeded
+	if res.Error != "" {
+		res.Status = StatusFailed
+	}
 	return res
 }
 
-// Tee duplicates record consumption with a side effect function.
-func Tee(in <-chan map[string]any, fn func(map[string]any)) <-chan map[string]any {
+// getConfig reads a pipeline definition, treating store failures as a missing pipeline.
+func (s *Service) getConfig(name string) (Config, bool) {
+	cfg, ok, err := s.store.Load(name)
+	if err != nil {
+		slog.Error("load pipeline", "pipeline", name, "error", err)
+		return Config{}, false
+	}
+	if ok {
+		cfg = cfg.withDefaults()
+	}
+	return cfg, ok
+}
+
+// Merge interleaves records from every input into one stream that closes once all inputs close.
+func Merge(ctx context.Context, ins ...<-chan map[string]any) <-chan map[string]any {
 	out := make(chan map[string]any)
+	var wg sync.WaitGroup
+	for _, in := range ins {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for record := range in {
+				select {
+				case <-ctx.Done():
+					return
+				case out <- record:
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
+
+// Fanout copies every record from in to n outputs; each output after the first receives a shallow
+// clone so destinations cannot observe each other's mutations. Every output must be drained.
+func Fanout(ctx context.Context, in <-chan map[string]any, n int) []<-chan map[string]any {
+	outs := make([]chan map[string]any, n)
+	result := make([]<-chan map[string]any, n)
+	for i := range outs {
+		outs[i] = make(chan map[string]any)
+		result[i] = outs[i]
+	}
+	go func() {
+		defer func() {
+			for _, out := range outs {
+				close(out)
+			}
+		}()
+		for record := range in {
+			for i, out := range outs {
+				rec := record
+				if i > 0 {
+					rec = maps.Clone(record)
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case out <- rec:
+				}
+			}
+		}
+	}()
+	return result
+}
+
+// Tee duplicates record consumption with side effect functions, calling each of fns in order for
+// every record before passing it on. Several observers share one goroutine. When ctx is cancelled
+// Tee stops forwarding and closes its output, so a consumer that stopped reading cannot strand it.
+func Tee(ctx context.Context, in <-chan map[string]any, fns ...func(map[string]any)) <-chan map[string]any {
+	return tee(ctx, in, 0, fns...)
+}
+
+// tee is Tee with an output buffer of size records.
+func tee(ctx context.Context, in <-chan map[string]any, size int, fns ...func(map[string]any)) <-chan map[string]any {
+	out := make(chan map[string]any, size)
 	go func() {
 		defer close(out)
 		for record := range in {
-			fn(record)
-			out <- record
+			for _, fn := range fns {
+				fn(record)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case out <- record:
+			}
 		}
 	}()
 	return out
@@ -0,0 +1,319 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"job-hunt/backend/internal/connectors"
+)
+
+// endless sends numbered records until ctx is cancelled, then closes its output.
+func endless(ctx context.Context) <-chan map[string]any {
+	out := make(chan map[string]any)
+	go func() {
+		defer close(out)
+		for i := 0; ; i++ {
+			select {
+			case <-ctx.Done():
+				return
+			case out <- map[string]any{"id": i}:
+			}
+		}
+	}()
+	return out
+}
+
+// waitClosed fails the test unless ch is closed within a second, discarding anything still in it.
+func waitClosed(t *testing.T, ch <-chan map[string]any) {
+	t.Helper()
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case _, ok := <-ch:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("channel not closed")
+		}
+	}
+}
+
+func TestTeeCancel(t *testing.T) {
+	before := runtime.NumGoroutine()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var seen atomic.Int64
+	outs := []<-chan map[string]any{Tee(ctx, endless(ctx), func(map[string]any) { seen.Add(1) })}
+	outs = append(outs, Tee(ctx, outs[0]))
+	outs = append(outs, Tee(ctx, outs[1], func(map[string]any) {}))
+	for range 10 {
+		<-outs[2]
+	}
+	if seen.Load() < 10 {
+		t.Fatalf("observer saw %d records, want at least 10", seen.Load())
+	}
+
+	// the consumer stops reading mid-stream; every Tee must still exit
+	cancel()
+	checkGoroutines(t, before)
+	for _, out := range outs {
+		waitClosed(t, out)
+	}
+}
+
+func TestBufferedTeeCancel(t *testing.T) {
+	before := runtime.NumGoroutine()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const size = 8
+	out := tee(ctx, endless(ctx), size)
+	deadline := time.Now().Add(time.Second)
+	for len(out) < size {
+		if time.Now().After(deadline) {
+			t.Fatalf("buffer holds %d records, want %d", len(out), size)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// nothing has been read; cancelling must stop the goroutine with its buffer still full
+	cancel()
+	checkGoroutines(t, before)
+	if len(out) != size {
+		t.Fatalf("buffer holds %d records after cancel, want %d", len(out), size)
+	}
+	waitClosed(t, out)
+}
+
+// stallingDestination takes a few records and then waits for the run to be cancelled.
+type stallingDestination struct {
+	connectors.NullDestination
+	stalled chan struct{}
+}
+
+func (d *stallingDestination) Info() connectors.Connector {
+	info := d.NullDestination.Info()
+	info.Name = "stalling"
+	return info
+}
+
+func (d *stallingDestination) Load(ctx context.Context, _ map[string]string, records <-chan map[string]any) error {
+	for range 3 {
+		<-records
+	}
+	close(d.stalled)
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestBufferedRunCancel(t *testing.T) {
+	svc := newTestService(t, NewMemoryStore())
+	dst := &stallingDestination{stalled: make(chan struct{})}
+	if err := svc.registry.RegisterDestination(dst); err != nil {
+		t.Fatalf("RegisterDestination: %v", err)
+	}
+	cfg := testConfig("stalling", 1000)
+	cfg.DestType = "stalling"
+	cfg.BufferSize = 64
+	if err := svc.Create(cfg); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	before := runtime.NumGoroutine()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan Result)
+	go func() { done <- svc.Run(ctx, "stalling") }()
+	<-dst.stalled
+	// let the source fill the buffers behind the stalled destination
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	select {
+	case res := <-done:
+		if res.Status != StatusFailed || res.Records != 3 {
+			t.Fatalf("Run = %s with %d records %q, want failed with 3", res.Status, res.Records, res.Error)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("cancelled run did not return")
+	}
+	checkGoroutines(t, before)
+}
+
+func TestEnvReferences(t *testing.T) {
+	t.Setenv("PIPELINE_SECRET_TEST_URL", "https://api.example.com/items")
+	t.Setenv("SERVER_ONLY_TEST_URL", "https://api.example.com/items")
+	svc := newTestService(t, NewMemoryStore())
+
+	cfg := testConfig("allowed", 3)
+	cfg.SourceConfig["url"] = "${PIPELINE_SECRET_TEST_URL}"
+	if err := svc.Create(cfg); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if res := svc.Run(context.Background(), "allowed"); res.Status != StatusSucceeded {
+		t.Fatalf("Run = %s %q", res.Status, res.Error)
+	}
+
+	for _, tc := range []struct{ name, ref, want string }{
+		{"disallowed", "${SERVER_ONLY_TEST_URL}", "SERVER_ONLY_TEST_URL may not be referenced"},
+		{"unset", "${PIPELINE_SECRET_UNSET_URL}", "PIPELINE_SECRET_UNSET_URL is not set"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := testConfig(tc.name, 3)
+			cfg.SourceConfig["url"] = tc.ref
+			err := svc.Create(cfg)
+			if !errors.Is(err, ErrValidation) || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("Create = %v, want a validation error containing %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestClone(t *testing.T) {
+	t.Setenv("PIPELINE_SECRET_PG_PASSWORD", "from-env")
+	svc := newTestService(t, NewMemoryStore())
+	if err := svc.Create(secretConfig("orders")); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if err := svc.Clone("orders", CloneOptions{Name: "orders-replica", DestConfig: map[string]string{"host": "replica"}}); err != nil {
+		t.Fatalf("Clone: %v", err)
+	}
+	clone, ok, err := svc.store.Load("orders-replica")
+	if err != nil || !ok {
+		t.Fatalf("clone not stored: %v", err)
+	}
+	if clone.SourceConfig["password"] != "hunter2" || clone.DestConfig["password"] != "${PIPELINE_SECRET_PG_PASSWORD}" {
+		t.Fatalf("clone secrets = %q %q, want the stored values", clone.SourceConfig["password"], clone.DestConfig["password"])
+	}
+	if clone.DestConfig["host"] != "replica" || clone.DestConfig["database"] != "analytics" {
+		t.Fatalf("clone destConfig = %v, want the override merged into the original", clone.DestConfig)
+	}
+	if orig, _, _ := svc.store.Load("orders"); orig.DestConfig["host"] != "warehouse" {
+		t.Fatalf("Clone changed the original's destConfig to %v", orig.DestConfig)
+	}
+
+	for _, tc := range []struct {
+		name string
+		from string
+		opts CloneOptions
+		want error
+	}{
+		{"existing name", "orders", CloneOptions{Name: "orders-replica"}, ErrPipelineExists},
+		{"missing original", "nope", CloneOptions{Name: "copy"}, ErrPipelineNotFound},
+		{"no name", "orders", CloneOptions{}, ErrValidation},
+		{"invalid override", "orders", CloneOptions{Name: "copy", SourceConfig: map[string]string{"port": "abc"}}, ErrValidation},
+		{"redacted override", "orders", CloneOptions{Name: "copy", SourceConfig: map[string]string{"password": Redacted}}, ErrValidation},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := svc.Clone(tc.from, tc.opts); !errors.Is(err, tc.want) {
+				t.Fatalf("Clone = %v, want %v", err, tc.want)
+			}
+		})
+	}
+	if _, ok := svc.Get("copy"); ok {
+		t.Fatal("a refused clone was stored")
+	}
+}
+
+func TestStats(t *testing.T) {
+	svc := newFailingService(t)
+	if stats, err := svc.Stats(); err != nil || stats != (Stats{}) {
+		t.Fatalf("Stats with nothing stored = %+v %v", stats, err)
+	}
+
+	failing := testConfig("failing", 20)
+	failing.DestType = "failing"
+	for _, cfg := range []Config{testConfig("orders", 3), failing, testConfig("idle", 1)} {
+		if err := svc.Create(cfg); err != nil {
+			t.Fatalf("Create(%s): %v", cfg.Name, err)
+		}
+	}
+	var want Stats
+	var totalMs int64
+	var failedRecords int
+	for _, name := range []string{"orders", "orders", "failing"} {
+		res := svc.Run(context.Background(), name)
+		want.Runs++
+		want.Records += res.Records
+		totalMs += res.DurationMs
+		if name == "failing" {
+			failedRecords = res.Records
+		}
+	}
+	want.Pipelines, want.Succeeded, want.Failed = 3, 2, 1
+	want.AvgDurationMs = float64(totalMs) / 3
+	if stats, err := svc.Stats(); err != nil || stats != want {
+		t.Fatalf("Stats = %+v %v, want %+v", stats, err, want)
+	}
+
+	// only the retained history counts
+	for range maxHistory {
+		svc.Run(context.Background(), "orders")
+	}
+	stats, err := svc.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Runs != maxHistory+1 || stats.Succeeded != maxHistory || stats.Records != maxHistory*3+failedRecords {
+		t.Fatalf("Stats past the history bound = %+v", stats)
+	}
+}
+
+func TestDedupe(t *testing.T) {
+	in := make(chan map[string]any)
+	go func() {
+		defer close(in)
+		for _, r := range []map[string]any{
+			{"id": 1, "n": "a"},
+			{"id": 2},
+			{"id": 1, "n": "b"},
+			{"id": "1"},
+			{"id": int64(1)},
+			{"id": nil},
+			{"id": nil},
+			{"other": 1},
+			{"other": 1},
+			{"id": 2},
+		} {
+			in <- r
+		}
+	}()
+	var dropped atomic.Int64
+	var kept []map[string]any
+	for r := range dedupe(context.Background(), in, "id", &dropped) {
+		kept = append(kept, r)
+	}
+	// the type is part of the key, and records without it always pass
+	want := []map[string]any{{"id": 1, "n": "a"}, {"id": 2}, {"id": "1"}, {"id": int64(1)}, {"id": nil}, {"other": 1}, {"other": 1}}
+	if !reflect.DeepEqual(kept, want) || dropped.Load() != 3 {
+		t.Fatalf("dedupe kept %v and dropped %d, want %v and 3", kept, dropped.Load(), want)
+	}
+}
+
+func TestDedupeRun(t *testing.T) {
+	svc := newTestService(t, NewMemoryStore())
+	cfg := testConfig("paged", 50)
+	cfg.DedupeKey = "page"
+	if err := svc.Create(cfg); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	// the http source puts 20 records on each page
+	res := svc.Run(context.Background(), "paged")
+	if res.Status != StatusSucceeded || res.Records != 3 || res.DuplicatesDropped != 47 {
+		t.Fatalf("Run = %s with %d records and %d duplicates, want 3 and 47", res.Status, res.Records, res.DuplicatesDropped)
+	}
+
+	// each run starts with nothing seen
+	res = svc.Run(context.Background(), "paged")
+	if res.Records != 3 || res.DuplicatesDropped != 47 {
+		t.Fatalf("second Run = %d records and %d duplicates, want 3 and 47", res.Records, res.DuplicatesDropped)
+	}
+}
@@ -0,0 +1,250 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+// RuleResult counts the records that passed and failed one quality rule during a run.
+type RuleResult struct {
+	Rule   string `json:"rule"`
+	Passed int    `json:"passed"`
+	Failed int    `json:"failed"`
+}
+
+// qualityRule is a compiled rule of the form `field op value` or `len(field) op value`, where value
+// is a JSON literal: id != null, payload == "x", len(payload) < 100, amount >= 0.
+type qualityRule struct {
+	text   string
+	field  string
+	length bool
+	op     string
+	value  any
+}
+
+// ruleCount holds one rule's tallies for a single attempt.
+type ruleCount struct {
+	passed, failed atomic.Int64
+}
+
+// compileRules parses every rule, reporting the first that is malformed.
+func compileRules(rules []string) ([]*qualityRule, error) {
+	compiled := make([]*qualityRule, 0, len(rules))
+	for i, text := range rules {
+		r, err := compileRule(text)
+		if err != nil {
+			return nil, fmt.Errorf("qualityRules[%d] %q: %w", i, text, err)
+		}
+		compiled = append(compiled, r)
+	}
+	return compiled, nil
+}
+
+func compileRule(text string) (*qualityRule, error) {
+	r := &qualityRule{text: text}
+	// field names cannot contain operator characters, so the first one starts the comparison
+	i := strings.IndexAny(text, "=!<>")
+	if i < 0 {
+		return nil, errors.New("missing comparison, expected one of == != < <= > >=")
+	}
+	r.op = text[i : i+1]
+	if i+1 < len(text) && text[i+1] == '=' {
+		r.op = text[i : i+2]
+	}
+	if r.op == "=" || r.op == "!" {
+		return nil, fmt.Errorf("unknown comparison %s, expected one of == != < <= > >=", r.op)
+	}
+	lhs, rhs := strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+len(r.op):])
+	if inner, ok := strings.CutPrefix(lhs, "len("); ok {
+		if lhs, ok = strings.CutSuffix(inner, ")"); !ok {
+			return nil, errors.New("len( is not closed")
+		}
+		lhs = strings.TrimSpace(lhs)
+		r.length = true
+	}
+	if lhs == "" || strings.ContainsAny(lhs, " \t()\"") {
+		return nil, errors.New("left side must be a field name or len(field)")
+	}
+	r.field = lhs
+	if err := json.Unmarshal([]byte(rhs), &r.value); err != nil {
+		return nil, errors.New(`right side must be a JSON literal such as 10, "text", true, or null`)
+	}
+	switch r.value.(type) {
+	case map[string]any, []any:
+		return nil, errors.New("right side must be a number, string, bool, or null")
+	case nil:
+		if r.op != "==" && r.op != "!=" {
+			return nil, errors.New("null can only be compared with == or !=")
+		}
+	}
+	if r.length {
+		if _, ok := r.value.(float64); !ok {
+			return nil, errors.New("len() must be compared with a number")
+		}
+	}
+	return r, nil
+}
+
+// check evaluates the rule against one record. A missing field compares as null.
+func (r *qualityRule) check(record map[string]any) error {
+	v := record[r.field]
+	if r.length {
+		n, ok := length(v)
+		if !ok {
+			return fmt.Errorf("%s is %T, which has no length", r.field, v)
+		}
+		v = float64(n)
+	}
+	ok, err := compare(v, r.op, r.value)
+	if err != nil {
+		return fmt.Errorf("%s: %w", r.field, err)
+	}
+	if !ok {
+		if v == nil {
+			return fmt.Errorf("%s is null", r.field)
+		}
+		return fmt.Errorf("%s is %v", r.field, v)
+	}
+	return nil
+}
+
+// length returns the rune count of a string or the size of an array or object; null is empty.
+func length(v any) (int, bool) {
+	switch v := v.(type) {
+	case nil:
+		return 0, true
+	case string:
+		return utf8.RuneCountInString(v), true
+	case []any:
+		return len(v), true
+	case map[string]any:
+		return len(v), true
+	}
+	return 0, false
+}
+
+// compare applies op to a record value and a rule literal. Numbers of any type compare numerically;
+// ordering is only defined between two numbers or two strings.
+func compare(v any, op string, literal any) (bool, error) {
+	if literal == nil || v == nil {
+		equal := literal == nil && v == nil
+		return equal == (op == "=="), nil
+	}
+	if a, ok := number(v); ok {
+		if b, ok := number(literal); ok {
+			return ordered(op, a, b), nil
+		}
+	}
+	if a, ok := v.(string); ok {
+		if b, ok := literal.(string); ok {
+			return ordered(op, a, b), nil
+		}
+	}
+	if a, ok := v.(bool); ok {
+		if b, ok := literal.(bool); ok && (op == "==" || op == "!=") {
+			return (a == b) == (op == "=="), nil
+		}
+	}
+	return false, fmt.Errorf("%T cannot be compared with %s %v", v, op, literal)
+}
+
+func ordered[T float64 | string](op string, a, b T) bool {
+	switch op {
+	case "==":
+		return a == b
+	case "!=":
+		return a != b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	default:
+		return a >= b
+	}
+}
+
+// number converts any Go numeric type to float64.
+func number(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// checkQuality evaluates every rule against each record, counting passes and failures per rule.
+// A record that breaks any rule is kept as a dead letter and dropped, or, when failRun is set,
+// cancels the run with the violation as the cause.
+func checkQuality(ctx context.Context, in <-chan map[string]any, rules []*qualityRule, counts []ruleCount, failRun bool, fail context.CancelCauseFunc, rejected *deadLetters) <-chan map[string]any {
+	out := make(chan map[string]any)
+	go func() {
+		defer close(out)
+		for record := range in {
+			var violations []string
+			for i, r := range rules {
+				if err := r.check(record); err != nil {
+					counts[i].failed.Add(1)
+					violations = append(violations, fmt.Sprintf("quality rule %q failed: %v", r.text, err))
+				} else {
+					counts[i].passed.Add(1)
+				}
+			}
+			if len(violations) > 0 {
+				err := errors.New(strings.Join(violations, "; "))
+				rejected.add(record, err)
+				if failRun {
+					fail(err)
+					return
+				}
+				continue
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case out <- record:
+			}
+		}
+	}()
+	return out
+}
+
+// ruleResults snapshots the per-rule tallies of an attempt.
+func ruleResults(rules []*qualityRule, counts []ruleCount) []RuleResult {
+	if len(rules) == 0 {
+		return nil
+	}
+	results := make([]RuleResult, len(rules))
+	for i, r := range rules {
+		results[i] = RuleResult{Rule: r.text, Passed: int(counts[i].passed.Load()), Failed: int(counts[i].failed.Load())}
+	}
+	return results
+}
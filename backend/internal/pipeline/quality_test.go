@@ -0,0 +1,139 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestQualityRules(t *testing.T) {
+	record := map[string]any{
+		"id":      int64(7),
+		"amount":  12.5,
+		"count":   uint8(3),
+		"name":    "héllo",
+		"active":  true,
+		"tags":    []any{"a", "b"},
+		"attrs":   map[string]any{"k": 1},
+		"missing": nil,
+	}
+	for _, tc := range []struct {
+		rule string
+		pass bool
+	}{
+		{"id == 7", true},
+		{"id != 7", false},
+		{"id < 8", true},
+		{"id <= 7", true},
+		{"id > 7", false},
+		{"id >= 7", true},
+		{"amount > 12", true},
+		{"count == 3", true},
+		{`name == "héllo"`, true},
+		{`name < "i"`, true},
+		{`name != "x"`, true},
+		{"active == true", true},
+		{"active != true", false},
+		{"id != null", true},
+		{"missing == null", true},
+		{"absent == null", true},
+		{"absent != null", false},
+		{"absent == 1", false},
+		{"len(name) == 5", true},
+		{"len(tags) == 2", true},
+		{"len(attrs) >= 1", true},
+		{"len(absent) == 0", true},
+		{"len( name ) < 5", false},
+		{"id==7", true},
+	} {
+		t.Run(tc.rule, func(t *testing.T) {
+			r, err := compileRule(tc.rule)
+			if err != nil {
+				t.Fatalf("compileRule: %v", err)
+			}
+			if err := r.check(record); (err == nil) != tc.pass {
+				t.Fatalf("check = %v, want pass %v", err, tc.pass)
+			}
+		})
+	}
+}
+
+func TestQualityRuleTypeMismatch(t *testing.T) {
+	record := map[string]any{"id": 1, "name": "x", "active": true}
+	for _, rule := range []string{`id == "1"`, "name > 1", "active > false", "len(id) == 1"} {
+		r, err := compileRule(rule)
+		if err != nil {
+			t.Fatalf("compileRule(%q): %v", rule, err)
+		}
+		if err := r.check(record); err == nil {
+			t.Fatalf("%q passed a record of the wrong type", rule)
+		}
+	}
+}
+
+func TestCompileRuleInvalid(t *testing.T) {
+	for _, rule := range []string{
+		"",
+		"id",
+		"id = 1",
+		"id ! 1",
+		"== 1",
+		"len(id == 1",
+		"len() == 1",
+		"a b == 1",
+		"id == nope",
+		"id == [1]",
+		`id == {"a":1}`,
+		"id < null",
+		`len(name) == "5"`,
+		"id ==",
+	} {
+		if _, err := compileRule(rule); err == nil {
+			t.Fatalf("compileRule(%q) succeeded", rule)
+		}
+	}
+}
+
+func TestQualityRulesAtCreate(t *testing.T) {
+	svc := newTestService(t, NewMemoryStore())
+	cfg := testConfig("checked", 1)
+	cfg.QualityRules = []string{"id != null", "id = 1"}
+	err := svc.Create(cfg)
+	if !errors.Is(err, ErrValidation) || !strings.Contains(err.Error(), "qualityRules[1]") {
+		t.Fatalf("Create = %v, want the malformed rule refused", err)
+	}
+	cfg.QualityRules = []string{"id != null"}
+	cfg.QualityPolicy = "ignore"
+	if err := svc.Create(cfg); !errors.Is(err, ErrValidation) {
+		t.Fatalf("Create with an unknown qualityPolicy = %v, want ErrValidation", err)
+	}
+}
+
+func TestQualityPolicy(t *testing.T) {
+	svc := newTestService(t, NewMemoryStore())
+	cfg := testConfig("skipping", 10)
+	cfg.QualityRules = []string{"id <= 6", "id != null"}
+	cfg.QualityPolicy = OnErrorSkip
+	if err := svc.Create(cfg); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	res := svc.Run(context.Background(), "skipping")
+	if res.Status != StatusSucceeded || res.Records != 6 || len(res.DeadLetters) != 4 {
+		t.Fatalf("Run = %s with %d records and %d dead letters, want 6 and 4", res.Status, res.Records, len(res.DeadLetters))
+	}
+	want := []RuleResult{{Rule: "id <= 6", Passed: 6, Failed: 4}, {Rule: "id != null", Passed: 10}}
+	if len(res.Quality) != 2 || res.Quality[0] != want[0] || res.Quality[1] != want[1] {
+		t.Fatalf("Quality = %+v, want %+v", res.Quality, want)
+	}
+
+	cfg.Name = "failing"
+	cfg.QualityPolicy = ""
+	if err := svc.Create(cfg); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	res = svc.Run(context.Background(), "failing")
+	if res.Status != StatusFailed || !strings.Contains(res.Error, `quality rule "id <= 6" failed`) {
+		t.Fatalf("Run = %s %q, want the rule violation", res.Status, res.Error)
+	}
+}
@@ -0,0 +1,156 @@
+package pipeline
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// DefaultWorkers is how many queued runs execute at once unless SetWorkers changes it.
+const DefaultWorkers = 4
+
+// MaxPriority is the highest Config.Priority.
+const MaxPriority = 9
+
+// runQueue holds asynchronous runs waiting for one of a bounded number of workers. Workers are
+// started as runs are queued and exit once the queue is empty. Its fields are guarded by Service.mu.
+type runQueue struct {
+	// pending is ordered by priority, highest first, then by enqueue time
+	pending []*queuedRun
+	// workers is the limit and busy the number of worker goroutines currently alive
+	workers int
+	busy    int
+	// closed is set by Shutdown; later runs are failed instead of queued
+	closed bool
+}
+
+// queuedRun is an asynchronous run waiting for a worker.
+type queuedRun struct {
+	id         string
+	name       string
+	priority   int
+	ctx        context.Context
+	enqueuedAt time.Time
+}
+
+// QueueStatus describes the run queue.
+type QueueStatus struct {
+	Workers int         `json:"workers"`
+	Active  int         `json:"active"`
+	Pending int         `json:"pending"`
+	Queued  []QueuedRun `json:"queued"`
+}
+
+// QueuedRun is one run waiting in the queue. Position 1 is the next to start.
+type QueuedRun struct {
+	JobID        string    `json:"jobId"`
+	PipelineName string    `json:"pipelineName"`
+	Priority     int       `json:"priority"`
+	Position     int       `json:"position"`
+	EnqueuedAt   time.Time `json:"enqueuedAt"`
+}
+
+// SetWorkers changes how many queued runs may execute at once; values below 1 are treated as 1.
+// Lowering it lets in-flight runs finish.
+func (s *Service) SetWorkers(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queue.workers = max(n, 1)
+	s.startWorkers()
+}
+
+// QueueStatus returns the worker limit, the number of busy workers, and the runs still waiting.
+func (s *Service) QueueStatus() QueueStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	status := QueueStatus{Workers: s.queue.workers, Active: s.queue.busy, Pending: len(s.queue.pending), Queued: []QueuedRun{}}
+	for i, q := range s.queue.pending {
+		status.Queued = append(status.Queued, QueuedRun{JobID: q.id, PipelineName: q.name, Priority: q.priority, Position: i + 1, EnqueuedAt: q.enqueuedAt})
+	}
+	return status
+}
+
+// enqueue adds a run to the queue behind every run of the same or higher priority and returns its
+// position, 0 when a worker starts it right away. The caller must hold s.mu.
+func (s *Service) enqueue(q *queuedRun) int {
+	i := len(s.queue.pending)
+	for i > 0 && s.queue.pending[i-1].priority < q.priority {
+		i--
+	}
+	s.queue.pending = slices.Insert(s.queue.pending, i, q)
+	position := i + 1
+	// a queue with runs waiting has every worker busy, so a new worker takes exactly this run
+	if s.startWorkers() > 0 {
+		return 0
+	}
+	return position
+}
+
+// startWorkers starts as many workers as the limit allows and the queue needs, returning how many
+// it started. The caller must hold s.mu.
+func (s *Service) startWorkers() int {
+	n := min(s.queue.workers-s.queue.busy, len(s.queue.pending))
+	for range n {
+		s.queue.busy++
+		go s.work()
+	}
+	return max(n, 0)
+}
+
+// work runs queued jobs one at a time until the queue is empty or the worker limit has been lowered.
+func (s *Service) work() {
+	for {
+		s.mu.Lock()
+		if len(s.queue.pending) == 0 || s.queue.busy > s.queue.workers {
+			s.queue.busy--
+			s.mu.Unlock()
+			return
+		}
+		next := s.queue.pending[0]
+		s.queue.pending = s.queue.pending[1:]
+		j := s.jobs[next.id]
+		j.result.Status = StatusRunning
+		j.result.StartedAt = time.Now()
+		s.mu.Unlock()
+
+		// the slot was taken when the run was queued
+		res := func() Result {
+			defer s.releaseRun()
+			return s.run(next.ctx, next.name, nil)
+		}()
+		j.cancel()
+
+		s.mu.Lock()
+		j.result = res
+		s.pruneJobs(j.result.FinishedAt)
+		s.mu.Unlock()
+	}
+}
+
+// dequeue removes job id from the queue, reporting whether it was waiting. The caller must hold s.mu.
+func (s *Service) dequeue(id string) bool {
+	i := slices.IndexFunc(s.queue.pending, func(q *queuedRun) bool { return q.id == id })
+	if i < 0 {
+		return false
+	}
+	s.queue.pending = slices.Delete(s.queue.pending, i, i+1)
+	return true
+}
+
+// queued reports whether the pipeline has a run waiting in the queue.
+func (s *Service) queued(name string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.ContainsFunc(s.queue.pending, func(q *queuedRun) bool { return q.name == name })
+}
+
+// closeQueue fails every waiting run and makes later ones fail instead of queueing.
+func (s *Service) closeQueue() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queue.closed = true
+	for _, q := range s.queue.pending {
+		s.failJob(s.jobs[q.id], errShuttingDown)
+	}
+	s.queue.pending = nil
+}
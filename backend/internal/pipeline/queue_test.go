@@ -0,0 +1,90 @@
+package pipeline
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestQueuePriority(t *testing.T) {
+	svc := newTestService(t, NewMemoryStore())
+	svc.SetWorkers(1)
+	dst := &stallingDestination{stalled: make(chan struct{})}
+	if err := svc.registry.RegisterDestination(dst); err != nil {
+		t.Fatalf("RegisterDestination: %v", err)
+	}
+	blocker := testConfig("blocker", 1000)
+	blocker.DestType = "stalling"
+	if err := svc.Create(blocker); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	var mu sync.Mutex
+	var order []string
+	svc.AddResultListener(ResultListenerFunc(func(_ context.Context, name string, _ Result) {
+		mu.Lock()
+		defer mu.Unlock()
+		order = append(order, name)
+	}))
+
+	blockerID, position, err := svc.RunAsync(context.Background(), "blocker")
+	if err != nil || position != 0 {
+		t.Fatalf("RunAsync(blocker) = %d %v, want it started at once", position, err)
+	}
+	<-dst.stalled
+
+	// each run is queued behind every run of the same or higher priority
+	var ids []string
+	for _, tc := range []struct {
+		name     string
+		priority int
+		position int
+	}{
+		{"low", 0, 1},
+		{"high", 5, 1},
+		{"low-later", 0, 3},
+		{"urgent", MaxPriority, 1},
+		{"high-later", 5, 3},
+	} {
+		cfg := testConfig(tc.name, 1)
+		cfg.Priority = tc.priority
+		if err := svc.Create(cfg); err != nil {
+			t.Fatalf("Create(%s): %v", tc.name, err)
+		}
+		id, position, err := svc.RunAsync(context.Background(), tc.name)
+		if err != nil {
+			t.Fatalf("RunAsync(%s): %v", tc.name, err)
+		}
+		if position != tc.position {
+			t.Fatalf("RunAsync(%s) position = %d, want %d", tc.name, position, tc.position)
+		}
+		ids = append(ids, id)
+	}
+
+	want := []string{"urgent", "high", "high-later", "low", "low-later"}
+	status := svc.QueueStatus()
+	if status.Workers != 1 || status.Active != 1 || status.Pending != len(want) {
+		t.Fatalf("QueueStatus = %+v", status)
+	}
+	for i, q := range status.Queued {
+		if q.PipelineName != want[i] || q.Position != i+1 {
+			t.Fatalf("queued[%d] = %s at %d, want %s at %d", i, q.PipelineName, q.Position, want[i], i+1)
+		}
+	}
+
+	if err := svc.Cancel("blocker"); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	waitJob(t, svc, blockerID)
+	for _, id := range ids {
+		if res := waitJob(t, svc, id); res.Status != StatusSucceeded {
+			t.Fatalf("job %s = %s %q", id, res.Status, res.Error)
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if got := order[1:]; !slices.Equal(got, want) {
+		t.Fatalf("runs finished in order %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,26 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+)
+
+// recovered turns the value of a recovered panic into an error and logs it with the stack of the
+// panicking goroutine. It must be called from the deferred function that recovered p.
+func recovered(ctx context.Context, what string, p any) error {
+	slog.ErrorContext(ctx, what+" panicked", "panic", p, "stack", string(debug.Stack()))
+	return fmt.Errorf("%s panicked: %v", what, p)
+}
+
+// safely calls fn, returning a panic in it as an error rather than letting it crash the process.
+// It guards calls into connector code, which may run on goroutines the HTTP server does not own.
+func safely(ctx context.Context, what string, fn func() error) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = recovered(ctx, what, p)
+		}
+	}()
+	return fn()
+}
@@ -0,0 +1,99 @@
+package pipeline
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"job-hunt/backend/internal/connectors"
+)
+
+// panickingSource is the http source with an Extract that panics.
+type panickingSource struct{ connectors.HTTPSource }
+
+func (s *panickingSource) Info() connectors.Connector {
+	info := s.HTTPSource.Info()
+	info.Name = "panicking"
+	return info
+}
+
+func (s *panickingSource) Extract(context.Context, map[string]string) (<-chan map[string]any, error) {
+	panic("connector bug")
+}
+
+// panickingDestination is the null destination with a Load that panics.
+type panickingDestination struct{ connectors.NullDestination }
+
+func (d *panickingDestination) Info() connectors.Connector {
+	info := d.NullDestination.Info()
+	info.Name = "panicking"
+	return info
+}
+
+func (d *panickingDestination) Load(context.Context, map[string]string, <-chan map[string]any) error {
+	panic("connector bug")
+}
+
+// waitJob polls an async job until it has finished and returns its result.
+func waitJob(t *testing.T, svc *Service, id string) Result {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		res, ok := svc.JobStatus(id)
+		if !ok {
+			t.Fatalf("job %s not found", id)
+		}
+		if res.Status != StatusQueued && res.Status != StatusRunning {
+			return res
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s still %s", id, res.Status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestRunRecoversPanic(t *testing.T) {
+	svc := newTestService(t, NewMemoryStore())
+	if err := svc.registry.RegisterSource(&panickingSource{}); err != nil {
+		t.Fatalf("RegisterSource: %v", err)
+	}
+	if err := svc.registry.RegisterDestination(&panickingDestination{}); err != nil {
+		t.Fatalf("RegisterDestination: %v", err)
+	}
+	svc.SetCircuitBreaker(0, 0)
+	if err := svc.Create(testConfig("healthy", 3)); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	for _, tc := range []struct{ name, source, dest, want string }{
+		{"extract", "panicking", "null", "extract panicked: connector bug"},
+		{"load", "http", "panicking", "load panicked: connector bug"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := testConfig(tc.name, 3)
+			cfg.SourceType, cfg.DestType = tc.source, tc.dest
+			if err := svc.Create(cfg); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+
+			res := svc.Run(context.Background(), tc.name)
+			if res.Status != StatusFailed || !strings.Contains(res.Error, tc.want) {
+				t.Fatalf("Run = %s %q, want failed with %q", res.Status, res.Error, tc.want)
+			}
+			id, _, err := svc.RunAsync(context.Background(), tc.name)
+			if err != nil {
+				t.Fatalf("RunAsync: %v", err)
+			}
+			if res := waitJob(t, svc, id); res.Status != StatusFailed || !strings.Contains(res.Error, tc.want) {
+				t.Fatalf("job = %s %q, want failed with %q", res.Status, res.Error, tc.want)
+			}
+
+			// the service keeps working after the panic
+			if res := svc.Run(context.Background(), "healthy"); res.Status != StatusSucceeded || res.Records != 3 {
+				t.Fatalf("Run after panic = %s with %d records %q", res.Status, res.Records, res.Error)
+			}
+		})
+	}
+}
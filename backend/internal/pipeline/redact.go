@@ -0,0 +1,142 @@
+package pipeline
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"job-hunt/backend/internal/connectors"
+)
+
+// Redacted replaces secret config values in configs returned by Get and List. Sending it back in
+// an Update keeps the stored value.
+const Redacted = "****"
+
+// secretKeyHints mark config keys as secret by name, for connectors whose schema does not flag them.
+var secretKeyHints = []string{"password", "secret", "token", "apikey", "api_key", "credential"}
+
+// connectorConfig is one connector config of a pipeline along with where it appears.
+type connectorConfig struct {
+	label  string
+	fields []connectors.ConfigField
+	config *map[string]string
+}
+
+// connectorConfigs lists every connector config of cfg with the schema reg has for it, pointing
+// into cfg so callers can replace the maps.
+func connectorConfigs(reg *connectors.Registry, cfg *Config) []connectorConfig {
+	// unknown connectors have no schema, leaving only the key name hints
+	sourceFields := func(name string) []connectors.ConfigField {
+		if src, err := reg.SourceByName(name); err == nil {
+			return src.Info().Config
+		}
+		return nil
+	}
+	destFields := func(name string) []connectors.ConfigField {
+		if dst, err := reg.DestinationByName(name); err == nil {
+			return dst.Info().Config
+		}
+		return nil
+	}
+	result := []connectorConfig{
+		{label: "sourceConfig", fields: sourceFields(cfg.SourceType), config: &cfg.SourceConfig},
+		{label: "destConfig", fields: destFields(cfg.DestType), config: &cfg.DestConfig},
+	}
+	for i := range cfg.Sources {
+		o := &cfg.Sources[i]
+		result = append(result, connectorConfig{label: fmt.Sprintf("sources[%d].config", i), fields: sourceFields(o.Type), config: &o.Config})
+	}
+	for i := range cfg.Destinations {
+		d := &cfg.Destinations[i]
+		result = append(result, connectorConfig{label: fmt.Sprintf("destinations[%d].config", i), fields: destFields(d.Type), config: &d.Config})
+	}
+	return result
+}
+
+// isSecret reports whether key holds a secret, either flagged in the connector schema or by its name.
+func isSecret(fields []connectors.ConfigField, key string) bool {
+	if i := slices.IndexFunc(fields, func(f connectors.ConfigField) bool { return f.Name == key }); i >= 0 && fields[i].Secret {
+		return true
+	}
+	lower := strings.ToLower(key)
+	return slices.ContainsFunc(secretKeyHints, func(hint string) bool { return strings.Contains(lower, hint) })
+}
+
+// isEnvReference reports whether value is a single ${NAME} reference, which names a secret
+// without revealing it and so is not redacted.
+func isEnvReference(value string) bool {
+	name, ok := strings.CutPrefix(value, "${")
+	if !ok {
+		return false
+	}
+	name, ok = strings.CutSuffix(name, "}")
+	return ok && isEnvName(name)
+}
+
+// rewriteConfigs returns a copy of cfg in which every connector config value is replaced by fn's
+// result. cfg itself, including its maps, is left untouched.
+func rewriteConfigs(reg *connectors.Registry, cfg Config, fn func(c connectorConfig, key, value string) (string, error)) (Config, error) {
+	cfg.Sources = slices.Clone(cfg.Sources)
+	cfg.Destinations = slices.Clone(cfg.Destinations)
+	for _, c := range connectorConfigs(reg, &cfg) {
+		if *c.config == nil {
+			continue
+		}
+		rewritten := make(map[string]string, len(*c.config))
+		for key, value := range *c.config {
+			v, err := fn(c, key, value)
+			if err != nil {
+				return Config{}, err
+			}
+			rewritten[key] = v
+		}
+		*c.config = rewritten
+	}
+	return cfg, nil
+}
+
+// redact returns a copy of cfg with every secret config value replaced by Redacted.
+func (s *Service) redact(cfg Config) Config {
+	cfg, _ = rewriteConfigs(s.registry, cfg, func(c connectorConfig, key, value string) (string, error) {
+		if value != "" && !isEnvReference(value) && isSecret(c.fields, key) {
+			return Redacted, nil
+		}
+		return value, nil
+	})
+	return cfg
+}
+
+// unredact puts the stored secret back wherever cfg still carries Redacted for a key current holds
+// in the same connector config. Redacted values with nothing to restore are left for check to reject.
+func (s *Service) unredact(cfg Config, current Config) Config {
+	stored := map[string]map[string]string{}
+	for _, c := range connectorConfigs(s.registry, &current) {
+		stored[c.label] = *c.config
+	}
+	cfg, _ = rewriteConfigs(s.registry, cfg, func(c connectorConfig, key, value string) (string, error) {
+		if old, ok := stored[c.label][key]; ok && value == Redacted {
+			return old, nil
+		}
+		return value, nil
+	})
+	return cfg
+}
+
+// checkRedacted rejects config values that are still Redacted, e.g. from a redacted export being
+// created elsewhere, and values that look sealed by EncryptedStore. Only the store writes those; one
+// submitted by a client would be stored as is and then fail to decrypt on every load.
+func (s *Service) checkRedacted(cfg Config) error {
+	for _, c := range connectorConfigs(s.registry, &cfg) {
+		for _, key := range slices.Sorted(maps.Keys(*c.config)) {
+			value := (*c.config)[key]
+			if value == Redacted {
+				return fmt.Errorf("%s %s is redacted; supply the value or a ${VAR} reference", c.label, key)
+			}
+			if strings.HasPrefix(value, encryptedPrefix) {
+				return fmt.Errorf("%s %s must not start with %q, which marks values encrypted by the store", c.label, key, encryptedPrefix)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package pipeline
+
+import "context"
+
+type requestIDKey struct{}
+
+// WithRequestID returns a context carrying the ID of the request that triggered the work, which runs
+// started with it record in their Result and log lines.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID carried by ctx, or "" when there is none (e.g. scheduled runs).
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
@@ -0,0 +1,108 @@
+package pipeline
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// ScheduleEntry describes when a scheduled pipeline runs next.
+type ScheduleEntry struct {
+	PipelineName string    `json:"pipelineName"`
+	Schedule     string    `json:"schedule"`
+	Enabled      bool      `json:"enabled"`
+	NextRun      time.Time `json:"nextRun,omitzero"`
+	Running      bool      `json:"running"`
+}
+
+// Schedules lists every pipeline with a schedule and its next run time, sorted by name.
+// Paused pipelines have no next run time.
+func (s *Service) Schedules() ([]ScheduleEntry, error) {
+	configs, err := s.store.All()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	var result []ScheduleEntry
+	for _, cfg := range configs {
+		if cfg.Schedule == "" {
+			continue
+		}
+		entry := ScheduleEntry{
+			PipelineName: cfg.Name,
+			Schedule:     cfg.Schedule,
+			Enabled:      cfg.IsEnabled(),
+			Running:      s.IsRunning(cfg.Name),
+		}
+		if sched, err := parseCron(cfg.Schedule); err == nil && entry.Enabled {
+			entry.NextRun = sched.next(now)
+		}
+		result = append(result, entry)
+	}
+	return result, nil
+}
+
+// PauseScheduler stops the scheduler from starting runs until ResumeScheduler is called.
+// Runs already in flight are not affected.
+func (s *Service) PauseScheduler() {
+	s.schedulerPaused.Store(true)
+}
+
+// ResumeScheduler lets a paused scheduler start runs again.
+func (s *Service) ResumeScheduler() {
+	s.schedulerPaused.Store(false)
+}
+
+// SchedulerPaused reports whether the scheduler is paused.
+func (s *Service) SchedulerPaused() bool {
+	return s.schedulerPaused.Load()
+}
+
+// StartScheduler runs due pipelines at the top of every minute until ctx is done.
+// Scheduled runs are detached from ctx so Shutdown can give them the same grace period as other runs.
+func (s *Service) StartScheduler(ctx context.Context) {
+	go func() {
+		for {
+			now := time.Now()
+			timer := time.NewTimer(now.Truncate(time.Minute).Add(time.Minute).Sub(now))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case tick := <-timer.C:
+				if !s.SchedulerPaused() {
+					s.trigger(tick)
+				}
+			}
+		}
+	}()
+}
+
+// trigger queues every pipeline whose schedule fires in the minute containing t. A pipeline that is
+// still running or queued from an earlier trigger is skipped rather than queued again.
+func (s *Service) trigger(t time.Time) {
+	configs, err := s.store.All()
+	if err != nil {
+		slog.Error("list scheduled pipelines", "error", err)
+		return
+	}
+	for _, cfg := range configs {
+		if cfg.Schedule == "" || !cfg.IsEnabled() {
+			continue
+		}
+		sched, err := parseCron(cfg.Schedule)
+		if err != nil || !sched.matches(t) {
+			continue
+		}
+		if s.IsRunning(cfg.Name) || s.queued(cfg.Name) {
+			slog.Warn("skipping scheduled run, pipeline still running", "pipeline", cfg.Name)
+			continue
+		}
+		id, position, err := s.RunAsync(context.Background(), cfg.Name)
+		if err != nil {
+			slog.Warn("skipping scheduled run", "pipeline", cfg.Name, "error", err)
+			continue
+		}
+		slog.Info("queued scheduled run", "pipeline", cfg.Name, "schedule", cfg.Schedule, "jobId", id, "queuePosition", position)
+	}
+}
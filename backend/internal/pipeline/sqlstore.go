@@ -0,0 +1,106 @@
+package pipeline
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// sqliteSchema creates the pipelines table. The connector config maps are stored as JSON text
+// next to the full definition so new Config fields never need a migration.
+const sqliteSchema = `CREATE TABLE IF NOT EXISTS pipelines (
+	name          TEXT PRIMARY KEY,
+	source_type   TEXT NOT NULL,
+	source_config TEXT NOT NULL,
+	dest_type     TEXT NOT NULL,
+	dest_config   TEXT NOT NULL,
+	definition    TEXT NOT NULL
+)`
+
+// SQLiteStore keeps definitions in a SQLite database. The driver is registered by the
+// binary, which keeps this package free of cgo and driver dependencies.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore ensures the schema exists on db.
+func NewSQLiteStore(db *sql.DB) (*SQLiteStore, error) {
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		return nil, fmt.Errorf("create pipelines table: %w", err)
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+func (s *SQLiteStore) Save(cfg Config) error {
+	sourceConfig, err := json.Marshal(cfg.SourceConfig)
+	if err != nil {
+		return fmt.Errorf("encode source config: %w", err)
+	}
+	destConfig, err := json.Marshal(cfg.DestConfig)
+	if err != nil {
+		return fmt.Errorf("encode destination config: %w", err)
+	}
+	definition, err := json.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("encode pipeline: %w", err)
+	}
+	_, err = s.db.Exec(`INSERT INTO pipelines (name, source_type, source_config, dest_type, dest_config, definition)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET
+			source_type = excluded.source_type,
+			source_config = excluded.source_config,
+			dest_type = excluded.dest_type,
+			dest_config = excluded.dest_config,
+			definition = excluded.definition`,
+		cfg.Name, cfg.SourceType, string(sourceConfig), cfg.DestType, string(destConfig), string(definition))
+	if err != nil {
+		return fmt.Errorf("save pipeline %s: %w", cfg.Name, err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) Load(name string) (Config, bool, error) {
+	var definition string
+	err := s.db.QueryRow(`SELECT definition FROM pipelines WHERE name = ?`, name).Scan(&definition)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Config{}, false, nil
+	}
+	if err != nil {
+		return Config{}, false, fmt.Errorf("load pipeline %s: %w", name, err)
+	}
+	var cfg Config
+	if err := json.Unmarshal([]byte(definition), &cfg); err != nil {
+		return Config{}, false, fmt.Errorf("decode pipeline %s: %w", name, err)
+	}
+	return cfg, true, nil
+}
+
+func (s *SQLiteStore) Delete(name string) error {
+	if _, err := s.db.Exec(`DELETE FROM pipelines WHERE name = ?`, name); err != nil {
+		return fmt.Errorf("delete pipeline %s: %w", name, err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) All() ([]Config, error) {
+	rows, err := s.db.Query(`SELECT definition FROM pipelines ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("list pipelines: %w", err)
+	}
+	defer rows.Close()
+
+	result := []Config{}
+	for rows.Next() {
+		var definition string
+		if err := rows.Scan(&definition); err != nil {
+			return nil, fmt.Errorf("list pipelines: %w", err)
+		}
+		var cfg Config
+		if err := json.Unmarshal([]byte(definition), &cfg); err != nil {
+			return nil, fmt.Errorf("decode pipeline: %w", err)
+		}
+		result = append(result, cfg)
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,32 @@
+//go:build sqlite
+
+package pipeline
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestSQLiteStore(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "pipelines.db"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer db.Close()
+	store, err := NewSQLiteStore(db)
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	testStore(t, store)
+
+	var sourceConfig string
+	if err := db.QueryRow(`SELECT source_config FROM pipelines WHERE name = 'b'`).Scan(&sourceConfig); err != nil {
+		t.Fatalf("read source_config: %v", err)
+	}
+	if sourceConfig == "" || sourceConfig[0] != '{' {
+		t.Fatalf("source_config = %q, want the JSON-encoded map", sourceConfig)
+	}
+}
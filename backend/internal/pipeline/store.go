@@ -0,0 +1,169 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// Store persists pipeline definitions. Implementations must be safe for concurrent use.
+type Store interface {
+	// Save inserts or replaces the definition with the same name.
+	Save(cfg Config) error
+	// Load returns the named definition and whether it exists.
+	Load(name string) (Config, bool, error)
+	// Delete removes the named definition; deleting a missing name is not an error.
+	Delete(name string) error
+	// All returns every definition sorted by name.
+	All() ([]Config, error)
+}
+
+// MemoryStore keeps definitions in a map and loses them on restart.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	configs map[string]Config
+}
+
+// NewMemoryStore builds an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{configs: map[string]Config{}}
+}
+
+func (m *MemoryStore) Save(cfg Config) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.configs[cfg.Name] = cfg
+	return nil
+}
+
+func (m *MemoryStore) Load(name string) (Config, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	cfg, ok := m.configs[name]
+	return cfg, ok, nil
+}
+
+func (m *MemoryStore) Delete(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.configs, name)
+	return nil
+}
+
+func (m *MemoryStore) All() ([]Config, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return sortedConfigs(m.configs), nil
+}
+
+// FileStore keeps definitions in memory and rewrites a JSON document on disk after every change.
+type FileStore struct {
+	mu      sync.RWMutex
+	path    string
+	configs map[string]Config
+}
+
+// NewFileStore loads the JSON document at path, treating a missing file as an empty store.
+func NewFileStore(path string) (*FileStore, error) {
+	f := &FileStore{path: path, configs: map[string]Config{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read pipeline store: %w", err)
+	}
+	if err := json.Unmarshal(data, &f.configs); err != nil {
+		return nil, fmt.Errorf("decode pipeline store %s: %w", path, err)
+	}
+	return f, nil
+}
+
+func (f *FileStore) Save(cfg Config) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	prev, existed := f.configs[cfg.Name]
+	f.configs[cfg.Name] = cfg
+	if err := f.write(); err != nil {
+		if existed {
+			f.configs[cfg.Name] = prev
+		} else {
+			delete(f.configs, cfg.Name)
+		}
+		return err
+	}
+	return nil
+}
+
+func (f *FileStore) Load(name string) (Config, bool, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	cfg, ok := f.configs[name]
+	return cfg, ok, nil
+}
+
+func (f *FileStore) Delete(name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	cfg, ok := f.configs[name]
+	if !ok {
+		return nil
+	}
+	delete(f.configs, name)
+	if err := f.write(); err != nil {
+		f.configs[name] = cfg
+		return err
+	}
+	return nil
+}
+
+func (f *FileStore) All() ([]Config, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return sortedConfigs(f.configs), nil
+}
+
+// write saves the definitions to a temp file and renames it over the store so a crash never leaves a partial file.
+// Callers hold the write lock.
+func (f *FileStore) write() error {
+	data, err := json.MarshalIndent(f.configs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode pipeline store: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("write pipeline store: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write pipeline store: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write pipeline store: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write pipeline store: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return fmt.Errorf("write pipeline store: %w", err)
+	}
+	return nil
+}
+
+// sortedConfigs flattens a config map into a slice ordered by name.
+func sortedConfigs(configs map[string]Config) []Config {
+	result := make([]Config, 0, len(configs))
+	for _, cfg := range configs {
+		result = append(result, cfg)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
@@ -0,0 +1,120 @@
+package pipeline
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"job-hunt/backend/internal/connectors"
+)
+
+// newTestService builds a service over the built-in connectors and the given store.
+func newTestService(t *testing.T, store Store) *Service {
+	t.Helper()
+	reg, err := connectors.NewRegistry()
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+	return NewService(reg, store)
+}
+
+// testConfig is a fast pipeline from the simulated HTTP source into the null destination.
+func testConfig(name string, records int) Config {
+	return Config{
+		Name:         name,
+		SourceType:   "http",
+		SourceConfig: map[string]string{"url": "https://api.example.com/items", "recordCount": strconv.Itoa(records), "pacingMs": "0"},
+		DestType:     "null",
+		DestConfig:   map[string]string{},
+	}
+}
+
+func TestServiceWithMemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+	svc := newTestService(t, store)
+
+	if err := svc.Create(testConfig("orders", 3)); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, ok, _ := store.Load("orders"); !ok {
+		t.Fatal("Create did not save the pipeline in the injected store")
+	}
+	if err := svc.Create(testConfig("orders", 3)); err != ErrPipelineExists {
+		t.Fatalf("second Create = %v, want ErrPipelineExists", err)
+	}
+
+	res := svc.Run(context.Background(), "orders")
+	if res.Status != StatusSucceeded || res.Records != 3 {
+		t.Fatalf("Run = %s with %d records (%q), want succeeded with 3", res.Status, res.Records, res.Error)
+	}
+
+	if err := svc.Delete("orders"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, ok := svc.Get("orders"); ok {
+		t.Fatal("Get found the pipeline after Delete")
+	}
+}
+
+// testStore checks the Store contract shared by every implementation.
+func testStore(t *testing.T, store Store) {
+	t.Helper()
+	cfg := testConfig("b", 1)
+	cfg.Transforms = []string{"lowercase-keys"}
+	for _, c := range []Config{cfg, testConfig("a", 2)} {
+		if err := store.Save(c); err != nil {
+			t.Fatalf("Save %s: %v", c.Name, err)
+		}
+	}
+
+	got, ok, err := store.Load("b")
+	if err != nil || !ok {
+		t.Fatalf("Load = %v, %v", ok, err)
+	}
+	if got.SourceConfig["url"] != cfg.SourceConfig["url"] || len(got.Transforms) != 1 {
+		t.Fatalf("Load returned %+v, want %+v", got, cfg)
+	}
+	if _, ok, err := store.Load("missing"); ok || err != nil {
+		t.Fatalf("Load of a missing name = %v, %v", ok, err)
+	}
+
+	all, err := store.All()
+	if err != nil {
+		t.Fatalf("All: %v", err)
+	}
+	if len(all) != 2 || all[0].Name != "a" || all[1].Name != "b" {
+		t.Fatalf("All returned %d configs, want a and b in order", len(all))
+	}
+
+	if err := store.Delete("a"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := store.Delete("a"); err != nil {
+		t.Fatalf("Delete of a missing name: %v", err)
+	}
+	if all, _ := store.All(); len(all) != 1 {
+		t.Fatalf("All after Delete returned %d configs, want 1", len(all))
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pipelines.json")
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	testStore(t, store)
+
+	reopened, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if _, ok, _ := reopened.Load("b"); !ok {
+		t.Fatal("definition did not survive reopening the file")
+	}
+}
@@ -0,0 +1,133 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"job-hunt/backend/internal/connectors"
+)
+
+// failingDestination takes after records and then fails the load.
+type failingDestination struct {
+	connectors.NullDestination
+	after int
+}
+
+func (d *failingDestination) Info() connectors.Connector {
+	info := d.NullDestination.Info()
+	info.Name = "failing"
+	return info
+}
+
+func (d *failingDestination) Load(ctx context.Context, _ map[string]string, records <-chan map[string]any) error {
+	for i := 0; i < d.after; i++ {
+		if _, ok := <-records; !ok {
+			return nil
+		}
+	}
+	return errors.New("destination rejected the batch")
+}
+
+// newFailingService builds a service whose "failing" destination fails after five records.
+func newFailingService(t *testing.T) *Service {
+	t.Helper()
+	svc := newTestService(t, NewMemoryStore())
+	if err := svc.registry.RegisterDestination(&failingDestination{after: 5}); err != nil {
+		t.Fatalf("RegisterDestination: %v", err)
+	}
+	// repeated failures must not trip the breaker and short-circuit later runs
+	svc.SetCircuitBreaker(0, 0)
+	return svc
+}
+
+// TestFailedLoadAttempt runs every stage that keeps state, with a destination that fails part way,
+// so go test -race catches stages still writing once the attempt is read.
+func TestFailedLoadAttempt(t *testing.T) {
+	svc := newFailingService(t)
+	cfg := testConfig("failing", 200)
+	cfg.DestType = "failing"
+	cfg.Transforms = []string{"lowercase-keys"}
+	cfg.DedupeKey = "id"
+	cfg.QualityRules = []string{"id > 0"}
+	cfg.BufferSize = 16
+	if err := svc.Create(cfg); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	for range 20 {
+		res := svc.Run(context.Background(), "failing")
+		if res.Status != StatusFailed || res.Error != "destination rejected the batch" {
+			t.Fatalf("Run = %s %q, want the load error", res.Status, res.Error)
+		}
+		if _, ok := svc.Cursor("failing"); ok {
+			t.Fatal("a failed run advanced the cursor")
+		}
+	}
+}
+
+// checkGoroutines fails the test if more goroutines are running than before, once those winding
+// down have had a moment to exit.
+func checkGoroutines(t *testing.T, before int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			buf := make([]byte, 1<<16)
+			t.Fatalf("%d goroutines left running, %d before:\n%s", runtime.NumGoroutine(), before, buf[:runtime.Stack(buf, true)])
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestFailedLoadCount checks that a failed run reports only the records its destination took and
+// leaves no stage goroutine behind.
+func TestFailedLoadCount(t *testing.T) {
+	svc := newFailingService(t)
+	cfg := testConfig("failing", 200)
+	cfg.DestType = "failing"
+	cfg.BufferSize = 16
+	if err := svc.Create(cfg); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	before := runtime.NumGoroutine()
+	for range 20 {
+		res := svc.Run(context.Background(), "failing")
+		if res.Status != StatusFailed || res.Records != 5 {
+			t.Fatalf("Run = %s with %d records, want failed with 5", res.Status, res.Records)
+		}
+	}
+	checkGoroutines(t, before)
+}
+
+// TestTruncatedSourceFailsRun checks that a source failing part way through fails the run instead
+// of ending it as if the file were complete.
+func TestTruncatedSourceFailsRun(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(connectors.JSONFileRootEnv, dir)
+	path := filepath.Join(dir, "records.json")
+	if err := os.WriteFile(path, []byte(`[{"id":1},{"id":2},{"id":`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	svc := newTestService(t, NewMemoryStore())
+	cfg := testConfig("truncated", 0)
+	cfg.SourceType = "jsonfile"
+	cfg.SourceConfig = map[string]string{"path": path}
+	if err := svc.Create(cfg); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	res := svc.Run(context.Background(), "truncated")
+	if res.Status != StatusFailed || !strings.Contains(res.Error, "element 2 is truncated") {
+		t.Fatalf("Run = %s %q, want the decode error", res.Status, res.Error)
+	}
+	if _, ok := svc.Cursor("truncated"); ok {
+		t.Fatal("a failed run advanced the cursor")
+	}
+}
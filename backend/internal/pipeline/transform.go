@@ -0,0 +1,203 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ErrSkipRecord signals that a transform wants the record dropped rather than failing the run.
+// Wrap it to give a reason; skipped records are kept as dead letters on the run result.
+var ErrSkipRecord = errors.New("skip record")
+
+// Transform rewrites a single record between extract and load.
+type Transform interface {
+	Apply(record map[string]any) (map[string]any, error)
+}
+
+// TransformFunc adapts a plain function to the Transform interface.
+type TransformFunc func(record map[string]any) (map[string]any, error)
+
+// Apply calls f(record).
+func (f TransformFunc) Apply(record map[string]any) (map[string]any, error) {
+	return f(record)
+}
+
+// transformFactory builds a transform from the pipeline's transformConfig.
+type transformFactory func(config map[string]string) (Transform, error)
+
+// builtinTransforms lists the transforms a Config may reference by name.
+var builtinTransforms = map[string]transformFactory{
+	"lowercase-keys": func(map[string]string) (Transform, error) { return TransformFunc(lowercaseKeys), nil },
+	"filter-fields":  newFieldFilter,
+	"expressions":    newExpressions,
+}
+
+// namedTransform keeps the config name next to the resolved transform for error reporting.
+type namedTransform struct {
+	name string
+	Transform
+}
+
+// resolveTransforms builds the named transforms in config order.
+func resolveTransforms(names []string, config map[string]string) ([]namedTransform, error) {
+	result := make([]namedTransform, 0, len(names))
+	for _, name := range names {
+		factory, ok := builtinTransforms[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown transform %s", name)
+		}
+		t, err := factory(config)
+		if err != nil {
+			return nil, fmt.Errorf("transform %s: %w", name, err)
+		}
+		result = append(result, namedTransform{name: name, Transform: t})
+	}
+	return result, nil
+}
+
+// transformRecords chains transforms over the record stream, dropping skipped records.
+// Any other transform error cancels the run with that error as the cause. Both skipped and
+// failing records are added to rejected as extracted.
+func transformRecords(ctx context.Context, in <-chan map[string]any, transforms []namedTransform, fail context.CancelCauseFunc, rejected *deadLetters) <-chan map[string]any {
+	out := make(chan map[string]any)
+	go func() {
+		defer close(out)
+		for extracted := range in {
+			record := extracted
+			var err error
+			for _, t := range transforms {
+				if record, err = t.Apply(record); err != nil {
+					err = fmt.Errorf("transform %s: %w", t.name, err)
+					break
+				}
+			}
+			if err != nil {
+				rejected.add(extracted, err)
+			}
+			if errors.Is(err, ErrSkipRecord) {
+				continue
+			}
+			if err != nil {
+				fail(err)
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case out <- record:
+			}
+		}
+	}()
+	return out
+}
+
+// deadLetters collects rejected records from the transform goroutine, keeping at most maxDeadLetters.
+type deadLetters struct {
+	mu      sync.Mutex
+	letters []DeadLetter
+}
+
+func (d *deadLetters) add(record map[string]any, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.letters) < maxDeadLetters {
+		d.letters = append(d.letters, DeadLetter{Record: record, Error: err.Error()})
+	}
+}
+
+func (d *deadLetters) list() []DeadLetter {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.letters
+}
+
+// lowercaseKeys rewrites every field name to lower case.
+func lowercaseKeys(record map[string]any) (map[string]any, error) {
+	out := make(map[string]any, len(record))
+	for k, v := range record {
+		out[strings.ToLower(k)] = v
+	}
+	return out, nil
+}
+
+// fieldFilter keeps only included fields and drops excluded ones; exclusion wins on conflict.
+type fieldFilter struct {
+	include map[string]bool
+	exclude map[string]bool
+}
+
+// newFieldFilter reads comma-separated includeFields and excludeFields from the transform config.
+// An empty include list passes every field through.
+func newFieldFilter(config map[string]string) (Transform, error) {
+	return &fieldFilter{
+		include: splitFields(config["includeFields"]),
+		exclude: splitFields(config["excludeFields"]),
+	}, nil
+}
+
+func (f *fieldFilter) Apply(record map[string]any) (map[string]any, error) {
+	out := make(map[string]any, len(record))
+	for k, v := range record {
+		if f.exclude[k] {
+			continue
+		}
+		if len(f.include) > 0 && !f.include[k] {
+			continue
+		}
+		out[k] = v
+	}
+	return out, nil
+}
+
+// fieldMapping renames source fields to destination fields, optionally dropping unmapped ones.
+type fieldMapping struct {
+	rename map[string]string
+	drop   bool
+}
+
+// newFieldMapping checks that every source field maps to a distinct, non-empty destination field.
+func newFieldMapping(rename map[string]string, drop bool) (Transform, error) {
+	targets := map[string]string{}
+	for from, to := range rename {
+		if from == "" || to == "" {
+			return nil, errors.New("mapping field names must not be empty")
+		}
+		if prev, ok := targets[to]; ok {
+			return nil, fmt.Errorf("mapping renames both %s and %s to %s", min(prev, from), max(prev, from), to)
+		}
+		targets[to] = from
+	}
+	return &fieldMapping{rename: rename, drop: drop}, nil
+}
+
+func (m *fieldMapping) Apply(record map[string]any) (map[string]any, error) {
+	out := make(map[string]any, len(record))
+	// unmapped fields are copied first so a renamed field wins over a passthrough of the same name
+	if !m.drop {
+		for k, v := range record {
+			if _, ok := m.rename[k]; !ok {
+				out[k] = v
+			}
+		}
+	}
+	for from, to := range m.rename {
+		if v, ok := record[from]; ok {
+			out[to] = v
+		}
+	}
+	return out, nil
+}
+
+// splitFields parses a comma-separated field list into a set.
+func splitFields(raw string) map[string]bool {
+	fields := map[string]bool{}
+	for _, field := range strings.Split(raw, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			fields[field] = true
+		}
+	}
+	return fields
+}
@@ -0,0 +1,124 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	// webhookAttempts is the number of deliveries tried before a notification is dropped.
+	webhookAttempts = 3
+	// webhookTimeout bounds a single delivery attempt.
+	webhookTimeout = 10 * time.Second
+)
+
+// webhookBackoff is the delay before the first redelivery; it doubles after every attempt.
+var webhookBackoff = time.Second
+
+var (
+	// webhookClient delivers run notifications, refusing to connect to an internal address even
+	// when a public host name resolves to one.
+	webhookClient = newWebhookClient()
+	// privateWebhookClient delivers them when SetPrivateWebhooks allows internal addresses.
+	privateWebhookClient = &http.Client{Timeout: webhookTimeout}
+)
+
+// SetPrivateWebhooks lets webhookUrl name loopback, private and link-local hosts, which are refused
+// by default so a pipeline definition cannot make the server probe its own network.
+func (s *Service) SetPrivateWebhooks(allow bool) {
+	s.privateWebhooks.Store(allow)
+}
+
+// newWebhookClient returns a client whose connections are checked against the internal address
+// ranges once the host name has been resolved, so a name pointing inside is caught as well.
+func newWebhookClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: webhookTimeout,
+		Control: func(network, address string, c syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			return checkWebhookHost(host)
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: webhookTimeout, Transport: transport}
+}
+
+// checkWebhookHost refuses localhost and any IP literal in the loopback, private, link-local,
+// multicast or unspecified ranges. Other host names are checked again when delivery connects.
+func checkWebhookHost(host string) error {
+	if name := strings.TrimSuffix(strings.ToLower(host), "."); name == "localhost" || strings.HasSuffix(name, ".localhost") {
+		return fmt.Errorf("webhookUrl host %s is internal", host)
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return nil
+	}
+	addr = addr.Unmap()
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() || addr.IsUnspecified() {
+		return fmt.Errorf("webhookUrl host %s is internal", host)
+	}
+	return nil
+}
+
+// notify posts the run result to the pipeline's webhook in the background. Delivery failures are
+// logged and never affect the result. Internal addresses are refused unless allowPrivate is set.
+func notify(url string, res Result, allowPrivate bool) {
+	body, err := json.Marshal(res)
+	if err != nil {
+		slog.Error("encode webhook payload", "pipeline", res.PipelineName, "error", err)
+		return
+	}
+	client := webhookClient
+	if allowPrivate {
+		client = privateWebhookClient
+	}
+	go func() {
+		backoff := webhookBackoff
+		for attempt := 1; ; attempt++ {
+			err := deliver(client, url, body)
+			if err == nil {
+				return
+			}
+			if attempt == webhookAttempts {
+				slog.Error("webhook delivery failed", "pipeline", res.PipelineName, "attempts", attempt, "error", err)
+				return
+			}
+			slog.Warn("webhook delivery failed, retrying",
+				"pipeline", res.PipelineName, "attempt", attempt, "backoffMs", backoff.Milliseconds(), "error", err)
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}()
+}
+
+// deliver makes one webhook POST, treating any non-2xx response as a failure.
+func deliver(client *http.Client, url string, body []byte) error {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// logLines makes the default logger send each line it writes to the returned channel for the rest
+// of the test, dropping lines once the channel is full rather than blocking the logging goroutine.
+func logLines(t *testing.T) <-chan string {
+	t.Helper()
+	lines := make(chan string, 64)
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(writerFunc(func(p []byte) (int, error) {
+		select {
+		case lines <- string(p):
+		default:
+		}
+		return len(p), nil
+	}), nil)))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+	return lines
+}
+
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) { return f(p) }
+
+func TestWebhookHost(t *testing.T) {
+	svc := newTestService(t, NewMemoryStore())
+	for _, tc := range []struct {
+		url string
+		ok  bool
+	}{
+		{"https://hooks.example.com/runs", true},
+		{"http://93.184.216.34:8080/", true},
+		{"http://localhost:8080/", false},
+		{"http://api.localhost/", false},
+		{"http://127.0.0.1/", false},
+		{"http://10.1.2.3/", false},
+		{"http://192.168.0.10/", false},
+		{"http://172.16.0.1/", false},
+		{"http://169.254.169.254/latest/meta-data", false},
+		{"http://[::1]:9000/", false},
+		{"http://[fe80::1]/", false},
+		{"http://[fd00::1]/", false},
+		{"http://[::ffff:127.0.0.1]/", false},
+		{"http://0.0.0.0/", false},
+	} {
+		t.Run(tc.url, func(t *testing.T) {
+			cfg := testConfig("hooked", 1)
+			cfg.WebhookURL = tc.url
+			err := svc.validate(cfg)
+			if tc.ok != (err == nil) {
+				t.Fatalf("validate = %v, want ok %v", err, tc.ok)
+			}
+			if err != nil && !errors.Is(err, ErrValidation) {
+				t.Fatalf("validate = %v, want ErrValidation", err)
+			}
+		})
+	}
+
+	svc.SetPrivateWebhooks(true)
+	cfg := testConfig("hooked", 1)
+	cfg.WebhookURL = "http://127.0.0.1:9000/"
+	if err := svc.Create(cfg); err != nil {
+		t.Fatalf("Create with private webhooks allowed: %v", err)
+	}
+}
+
+// TestWebhookDialGuard checks that delivery refuses an internal address a host name resolved to,
+// which validation alone cannot see.
+func TestWebhookDialGuard(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	if err := deliver(webhookClient, srv.URL, []byte("{}")); err == nil || !strings.Contains(err.Error(), "is internal") {
+		t.Fatalf("deliver to %s = %v, want it refused", srv.URL, err)
+	}
+	if err := deliver(privateWebhookClient, srv.URL, []byte("{}")); err != nil {
+		t.Fatalf("deliver with private webhooks allowed: %v", err)
+	}
+}
+
+func TestWebhookDelivery(t *testing.T) {
+	got := make(chan Result, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var res Result
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("webhook got %s with Content-Type %q", r.Method, r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&res); err != nil {
+			t.Errorf("decode webhook body: %v", err)
+		}
+		got <- res
+	}))
+	defer srv.Close()
+
+	svc := newTestService(t, NewMemoryStore())
+	svc.SetPrivateWebhooks(true)
+	cfg := testConfig("hooked", 3)
+	cfg.WebhookURL = srv.URL
+	if err := svc.Create(cfg); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	res := svc.Run(context.Background(), "hooked")
+	select {
+	case posted := <-got:
+		if posted.PipelineName != "hooked" || posted.Status != res.Status || posted.Records != 3 {
+			t.Fatalf("webhook got %+v, want the result %+v", posted, res)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook was not delivered")
+	}
+}
+
+func TestWebhookFailureLogged(t *testing.T) {
+	prev := webhookBackoff
+	webhookBackoff = time.Millisecond
+	t.Cleanup(func() { webhookBackoff = prev })
+	lines := logLines(t)
+
+	calls := make(chan struct{}, webhookAttempts+1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		calls <- struct{}{}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	svc := newTestService(t, NewMemoryStore())
+	svc.SetPrivateWebhooks(true)
+	cfg := testConfig("hooked", 1)
+	cfg.WebhookURL = srv.URL
+	if err := svc.Create(cfg); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if res := svc.Run(context.Background(), "hooked"); res.Status != StatusSucceeded {
+		t.Fatalf("Run = %s %q; a failing webhook must not affect the run", res.Status, res.Error)
+	}
+
+	retries := 0
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case line := <-lines:
+			switch {
+			case strings.Contains(line, "webhook delivery failed, retrying"):
+				retries++
+			case strings.Contains(line, "webhook delivery failed"):
+				if retries != webhookAttempts-1 || !strings.Contains(line, "attempts=3") || !strings.Contains(line, "500") {
+					t.Fatalf("after %d retries got %q", retries, line)
+				}
+				if len(calls) != webhookAttempts {
+					t.Fatalf("webhook called %d times, want %d", len(calls), webhookAttempts)
+				}
+				return
+			}
+		case <-timeout:
+			t.Fatalf("no final delivery failure logged after %d retries", retries)
+		}
+	}
+}